@@ -0,0 +1,92 @@
+package breaker
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// AdminHandler returns an http.Handler for inspecting and managing the
+// circuits in reg:
+//
+//	GET  /circuits              list every circuit's snapshot
+//	GET  /circuits/{name}       one circuit's snapshot
+//	POST /circuits/{name}/reset call Reset
+//	POST /circuits/{name}/open  call ForceOpen
+//
+// Responses are JSON. The POST routes respond with the circuit's snapshot
+// after the change. Unknown circuits get 404. The handler performs no
+// authentication; wrap it in middleware before exposing it:
+//
+//	mux.Handle("/admin/", http.StripPrefix("/admin", requireAdmin(breaker.AdminHandler(reg))))
+func AdminHandler(reg *Registry) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /circuits", func(w http.ResponseWriter, r *http.Request) {
+		circuits := reg.Circuits()
+		resp := make([]adminSnapshot, len(circuits))
+		for i, c := range circuits {
+			resp[i] = newAdminSnapshot(c.Snapshot())
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+	mux.HandleFunc("GET /circuits/{name}", adminCircuit(reg, func(c *Circuit) {}))
+	mux.HandleFunc("POST /circuits/{name}/reset", adminCircuit(reg, func(c *Circuit) { c.ResetAndReport() }))
+	mux.HandleFunc("POST /circuits/{name}/open", adminCircuit(reg, (*Circuit).ForceOpen))
+	return mux
+}
+
+// adminCircuit returns a handler that applies action to the named circuit
+// and responds with its snapshot.
+func adminCircuit(reg *Registry, action func(*Circuit)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		c, ok := reg.Lookup(r.PathValue("name"))
+		if !ok {
+			writeJSON(w, http.StatusNotFound, adminError{Error: "circuit not found"})
+			return
+		}
+		action(c)
+		writeJSON(w, http.StatusOK, newAdminSnapshot(c.Snapshot()))
+	}
+}
+
+// adminSnapshot is the JSON form of a Snapshot.
+type adminSnapshot struct {
+	Name             string            `json:"name"`
+	State            string            `json:"state"`
+	Failures         int               `json:"failures"`
+	Successes        int               `json:"successes"`
+	InFlight         int               `json:"in_flight"`
+	FailureThreshold int               `json:"failure_threshold"`
+	OpenedAt         *time.Time        `json:"opened_at,omitempty"`
+	LastErr          string            `json:"last_error,omitempty"`
+	Tags             map[string]string `json:"tags,omitempty"`
+	DryRun           bool              `json:"dry_run"`
+}
+
+func newAdminSnapshot(s Snapshot) adminSnapshot {
+	a := adminSnapshot{
+		Name:             s.Name,
+		State:            s.State.String(),
+		Failures:         s.Failures,
+		Successes:        s.Successes,
+		InFlight:         s.InFlight,
+		FailureThreshold: s.FailureThreshold,
+		LastErr:          s.LastErr,
+		Tags:             s.Tags,
+		DryRun:           s.DryRun,
+	}
+	if !s.OpenedAt.IsZero() {
+		a.OpenedAt = &s.OpenedAt
+	}
+	return a
+}
+
+type adminError struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
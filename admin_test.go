@@ -0,0 +1,109 @@
+package breaker_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bjaus/breaker"
+	"github.com/stretchr/testify/suite"
+)
+
+type AdminSuite struct {
+	suite.Suite
+	clock   *fakeClock
+	reg     *breaker.Registry
+	handler http.Handler
+}
+
+func TestAdminSuite(t *testing.T) {
+	suite.Run(t, new(AdminSuite))
+}
+
+func (s *AdminSuite) SetupTest() {
+	s.clock = newFakeClock()
+	s.reg = breaker.NewRegistry()
+	s.handler = breaker.AdminHandler(s.reg)
+}
+
+func (s *AdminSuite) serve(method, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+	return rec
+}
+
+func (s *AdminSuite) decode(rec *httptest.ResponseRecorder, v any) {
+	s.Equal("application/json", rec.Header().Get("Content-Type"))
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), v))
+}
+
+func (s *AdminSuite) TestList() {
+	s.reg.Get("search", breaker.WithClock(s.clock))
+	c := s.reg.Get("payments", breaker.WithFailureThreshold(1), breaker.WithClock(s.clock))
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+
+	rec := s.serve(http.MethodGet, "/circuits")
+
+	s.Equal(http.StatusOK, rec.Code)
+	var got []map[string]any
+	s.decode(rec, &got)
+	s.Require().Len(got, 2)
+	s.Equal("payments", got[0]["name"])
+	s.Equal("open", got[0]["state"])
+	s.Equal(errTest.Error(), got[0]["last_error"])
+	s.Equal("search", got[1]["name"])
+	s.Equal("closed", got[1]["state"])
+}
+
+func (s *AdminSuite) TestGet() {
+	s.reg.Get("payments", breaker.WithClock(s.clock))
+
+	rec := s.serve(http.MethodGet, "/circuits/payments")
+
+	s.Equal(http.StatusOK, rec.Code)
+	var got map[string]any
+	s.decode(rec, &got)
+	s.Equal("payments", got["name"])
+	s.Equal("closed", got["state"])
+}
+
+func (s *AdminSuite) TestOpenAndReset() {
+	c := s.reg.Get("payments", breaker.WithClock(s.clock))
+
+	rec := s.serve(http.MethodPost, "/circuits/payments/open")
+	s.Equal(http.StatusOK, rec.Code)
+	s.Equal(breaker.Open, c.State())
+	var got map[string]any
+	s.decode(rec, &got)
+	s.Equal("open", got["state"])
+
+	rec = s.serve(http.MethodPost, "/circuits/payments/reset")
+	s.Equal(http.StatusOK, rec.Code)
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *AdminSuite) TestUnknownCircuit() {
+	for _, tc := range []struct{ method, path string }{
+		{http.MethodGet, "/circuits/missing"},
+		{http.MethodPost, "/circuits/missing/reset"},
+		{http.MethodPost, "/circuits/missing/open"},
+	} {
+		rec := s.serve(tc.method, tc.path)
+		s.Equal(http.StatusNotFound, rec.Code, tc.path)
+	}
+	_, ok := s.reg.Lookup("missing")
+	s.False(ok, "expected admin requests not to create circuits")
+}
+
+func (s *AdminSuite) TestWrongMethod() {
+	s.reg.Get("payments", breaker.WithClock(s.clock))
+
+	rec := s.serve(http.MethodGet, "/circuits/payments/reset")
+
+	s.Equal(http.StatusMethodNotAllowed, rec.Code)
+	s.Equal(breaker.Closed, s.reg.Get("payments").State())
+}
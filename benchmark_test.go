@@ -10,6 +10,7 @@ func BenchmarkCircuit_Do_Success(b *testing.B) {
 	ctx := context.Background()
 	circuit := New("bench")
 
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		circuit.Do(ctx, func(ctx context.Context) error {
@@ -22,6 +23,7 @@ func BenchmarkCircuit_Do_Failure(b *testing.B) {
 	ctx := context.Background()
 	errTest := errors.New("test error")
 
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		circuit := New("bench", WithFailureThreshold(b.N+1))
@@ -39,6 +41,7 @@ func BenchmarkCircuit_Do_Open(b *testing.B) {
 		return errors.New("trip")
 	})
 
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		circuit.Do(ctx, func(ctx context.Context) error {
@@ -51,6 +54,7 @@ func BenchmarkCircuit_Do_Parallel(b *testing.B) {
 	ctx := context.Background()
 	circuit := New("bench")
 
+	b.ReportAllocs()
 	b.ResetTimer()
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
@@ -64,8 +68,70 @@ func BenchmarkCircuit_Do_Parallel(b *testing.B) {
 func BenchmarkCircuit_State(b *testing.B) {
 	circuit := New("bench")
 
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		circuit.State()
 	}
 }
+
+func BenchmarkCircuit_Do_ParallelHighConcurrency(b *testing.B) {
+	ctx := context.Background()
+	circuit := New("bench")
+
+	b.ReportAllocs()
+	b.SetParallelism(64)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			circuit.Do(ctx, func(ctx context.Context) error {
+				return nil
+			})
+		}
+	})
+}
+
+func BenchmarkCircuit_Do_ZeroAlloc(b *testing.B) {
+	ctx := context.Background()
+	circuit := New("bench")
+	fn := func(ctx context.Context) error {
+		return nil
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() {
+		circuit.Do(ctx, fn)
+	}); allocs != 0 {
+		b.Fatalf("Do allocated %v objects per call, want 0", allocs)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		circuit.Do(ctx, fn)
+	}
+}
+
+func BenchmarkCircuit_State_Closed(b *testing.B) {
+	circuit := New("bench")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			circuit.State()
+		}
+	})
+}
+
+func BenchmarkCircuit_Tripped(b *testing.B) {
+	circuit := New("bench", WithFailureThreshold(1))
+	circuit.ForceOpen()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			circuit.Tripped()
+		}
+	})
+}
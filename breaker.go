@@ -2,8 +2,14 @@ package breaker
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
+	"fmt"
+	"math"
+	"runtime/debug"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -35,29 +41,269 @@ func (s State) String() string {
 	}
 }
 
+// Code returns a stable numeric code for the state, ordered by severity:
+// 0 for Closed, 1 for HalfOpen, and 2 for Open. Unlike the State values
+// themselves, these codes are guaranteed not to change, which makes them
+// suitable for metrics gauges. Unknown states return -1.
+func (s State) Code() int {
+	switch s {
+	case Closed:
+		return 0
+	case HalfOpen:
+		return 1
+	case Open:
+		return 2
+	default:
+		return -1
+	}
+}
+
+// StateFromCode returns the state for a code returned by State.Code.
+// It reports false if code is unknown.
+func StateFromCode(code int) (State, bool) {
+	switch code {
+	case 0:
+		return Closed, true
+	case 1:
+		return HalfOpen, true
+	case 2:
+		return Open, true
+	default:
+		return Closed, false
+	}
+}
+
 // Func is the function signature for protected operations.
 type Func func(ctx context.Context) error
 
 // Condition determines whether an error should count as a failure.
 type Condition func(error) bool
 
+// ContextCondition determines whether an error counts as a failure,
+// with access to the context the call was made with.
+type ContextCondition func(ctx context.Context, err error) bool
+
 // OnStateChangeFunc is called when the circuit changes state.
 type OnStateChangeFunc func(name string, from, to State)
 
 // OnCallFunc is called after each call attempt.
 type OnCallFunc func(name string, state State, err error)
 
+// OnCallTaggedFunc is called after each call attempt with the circuit's
+// tags. The tags map is a copy owned by the hook.
+type OnCallTaggedFunc func(name string, tags map[string]string, state State, err error)
+
 // OnRejectFunc is called when a call is rejected due to open circuit.
 type OnRejectFunc func(name string)
 
+// OnRejectReasonFunc is called when a call is rejected, with the reason.
+type OnRejectReasonFunc func(name string, reason RejectReason)
+
+// OnAutoResetFunc is called when WithAutoReset closes a stuck circuit.
+type OnAutoResetFunc func(name string)
+
+// OnRecoverFunc is called when a circuit recovers from HalfOpen to Closed.
+// downtime is how long the circuit was not Closed.
+type OnRecoverFunc func(name string, downtime time.Duration)
+
+// OnProbeFunc is called with the outcome of each half-open trial call.
+type OnProbeFunc func(name string, success bool)
+
 // ErrOpen is returned when the circuit is open and rejecting requests.
 var ErrOpen = errors.New("circuit open")
 
+// OpenError is returned instead of ErrOpen when the circuit rejects a call
+// after opening because of a failure. errors.Is(err, ErrOpen) reports true
+// for it, and Unwrap returns the failure that opened the circuit.
+type OpenError struct {
+	// Name is the circuit's FullName.
+	Name string
+
+	// ID is the circuit's ID.
+	ID string
+
+	// OpenedAt is when the circuit opened.
+	OpenedAt time.Time
+
+	// OpenDuration is how long the circuit stays open before admitting
+	// trial calls, including any WithCooldown extension.
+	OpenDuration time.Duration
+
+	// Cause is the error that opened the circuit. It is nil if the circuit
+	// was opened by a shared Budget or started open.
+	Cause error
+
+	clock Clock
+}
+
+// RetryAfter returns how long until the circuit admits trial calls,
+// measured with the circuit's clock. It is zero once the circuit is
+// half-open, which suits a Retry-After header.
+func (e *OpenError) RetryAfter() time.Duration {
+	var clock Clock = realClock{}
+	if e.clock != nil {
+		clock = e.clock
+	}
+	return max(e.OpenDuration-clock.Now().Sub(e.OpenedAt), 0)
+}
+
+// Error implements the error interface.
+func (e *OpenError) Error() string {
+	if e.Cause == nil {
+		return ErrOpen.Error()
+	}
+	return ErrOpen.Error() + ": " + e.Cause.Error()
+}
+
+// Is reports whether target is ErrOpen.
+func (e *OpenError) Is(target error) bool {
+	return target == ErrOpen
+}
+
+// Unwrap returns the error that opened the circuit.
+func (e *OpenError) Unwrap() error {
+	return e.Cause
+}
+
 // IsOpen reports whether err is because the circuit is open.
 func IsOpen(err error) bool {
 	return errors.Is(err, ErrOpen)
 }
 
+// OpenFor wraps err so that, if it opens the circuit, the circuit stays
+// open for d instead of the configured open duration. Use it to honor a
+// wait time the downstream asked for, such as a Retry-After header. It has
+// no effect if err does not open the circuit or d is not positive.
+func OpenFor(err error, d time.Duration) error {
+	return &openForError{err: err, d: d}
+}
+
+type openForError struct {
+	err error
+	d   time.Duration
+}
+
+func (e *openForError) Error() string { return e.err.Error() }
+func (e *openForError) Unwrap() error { return e.err }
+
+// ErrDraining is returned when the circuit is draining and rejecting new requests.
+var ErrDraining = errors.New("circuit draining")
+
+// IsDraining reports whether err is because the circuit is draining.
+func IsDraining(err error) bool {
+	return errors.Is(err, ErrDraining)
+}
+
+// ErrShutdown is returned when the circuit has been closed with Close.
+var ErrShutdown = errors.New("circuit shut down")
+
+// ErrReentrant is returned by a circuit with WithReentrancyCheck when Do
+// is called from within a function already running under that circuit.
+var ErrReentrant = errors.New("reentrant call on circuit")
+
+// ErrForcedFailure is returned by Do in place of running the function when
+// the context comes from WithForceFail.
+var ErrForcedFailure = errors.New("forced failure")
+
+// Unrecorded wraps err so that, returned from the function passed to Do or
+// reported with Token.Fail, the call's outcome is not recorded: it counts
+// as neither a success nor a failure, no call hooks fire, and a half-open
+// trial slot the call held is given back. Do returns err itself. Use it
+// for calls abandoned for reasons that say nothing about the dependency,
+// such as a caller cancelling. Unrecorded(nil) returns nil.
+func Unrecorded(err error) error {
+	if err == nil {
+		return nil
+	}
+	return unrecordedError{err}
+}
+
+// unrecordedError is the error Unrecorded returns.
+type unrecordedError struct {
+	err error
+}
+
+func (e unrecordedError) Error() string {
+	return e.err.Error()
+}
+
+func (e unrecordedError) Unwrap() error {
+	return e.err
+}
+
+// ErrCallLimitExceeded is the cause of an OpenError when WithCallLimit
+// opened the circuit. It wraps ErrOpen, so IsOpen reports true for it.
+var ErrCallLimitExceeded = fmt.Errorf("call limit exceeded: %w", ErrOpen)
+
+// ConfigError describes an invalid circuit configuration.
+type ConfigError struct {
+	Field   string
+	Message string
+}
+
+// Error implements the error interface.
+func (e *ConfigError) Error() string {
+	return "breaker: invalid " + e.Field + ": " + e.Message
+}
+
+// PanicError is the failure recorded when fn panics under
+// WithRecoverPanics.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+// Error implements the error interface.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("breaker: panic: %v", e.Value)
+}
+
+// Unwrap returns Value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// CircuitError wraps an error returned by fn when WithRichErrors is set,
+// adding what the circuit made of the call. Its message is that of Err.
+type CircuitError struct {
+	// Err is the error fn returned.
+	Err error
+
+	// Name is the circuit's FullName.
+	Name string
+
+	// ID is the circuit's ID.
+	ID string
+
+	// State is the state the circuit was in when the call was admitted.
+	State State
+
+	// IsTrip reports whether this call opened the circuit, from Closed or
+	// by failing a half-open trial.
+	IsTrip bool
+}
+
+// Error implements the error interface.
+func (e *CircuitError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns Err.
+func (e *CircuitError) Unwrap() error {
+	return e.Err
+}
+
+// IsTrip reports whether err is a *CircuitError for a call that opened the
+// circuit. It is always false without WithRichErrors.
+func IsTrip(err error) bool {
+	var ce *CircuitError
+	return errors.As(err, &ce) && ce.IsTrip
+}
+
 // Default values.
 const (
 	DefaultFailureThreshold = 5
@@ -68,157 +314,1392 @@ const (
 
 // Circuit is a circuit breaker. Safe for concurrent use.
 type Circuit struct {
-	name string
-	cfg  config
+	name     string
+	fullName string
+	id       string
+	cfg      config
+
+	// gate is NewConditional's enabled function. It is set before the
+	// circuit is returned and never changes.
+	gate func() bool
+
+	mu            sync.RWMutex
+	state         State
+	failures      int
+	successes     int
+	halfOpenCnt   int
+	halfOpenGen   uint64
+	trials        trialHeap
+	trialSeq      uint64
+	changed       chan struct{}
+	openedAt      time.Time
+	openFor       time.Duration
+	halfOpenAt    time.Time
+	halfOpenedAt  time.Time
+	flaps         int
+	closedCalls   int
+	lastFailureAt time.Time
+	lastProbeAt   time.Time
+	openErr       *OpenError
+	lastErr       error
+	downSince     time.Time
+	recoveredAt   time.Time
+	closedAt      time.Time
+	ramping       bool
+	createdAt     time.Time
+	warmupLeft    int
+
+	autoResetStop func()
+	autoResetGen  uint64
 
-	mu          sync.Mutex
-	state       State
-	failures    int
-	successes   int
-	halfOpenCnt int
-	openedAt    time.Time
+	// stateHint mirrors state for lock-free reads. It is written under mu
+	// but may lag behind a lazy Open to HalfOpen transition.
+	stateHint atomic.Int32
+
+	// openUntil is when the current Open period ends, in Unix nanoseconds
+	// on the circuit's clock, for Tripped. It is written under mu.
+	openUntil atomic.Int64
+
+	disabled  atomic.Bool
+	inFlight  atomic.Int64
+	draining  atomic.Bool
+	drained   chan struct{}
+	drainOnce sync.Once
+
+	totalCalls      atomic.Int64
+	totalRejections atomic.Int64
+
+	// broadcast serves Subscribe. It is created by the first Subscribe and
+	// written under mu.
+	broadcast *broadcaster
+
+	shutdown  atomic.Bool
+	stop      chan struct{}
+	stopOnce  sync.Once
+	probeDone sync.WaitGroup
+
+	// probeMu guards probeStop, the timer for the next active probe, and
+	// orders probeDone.Add against Close.
+	probeMu   sync.Mutex
+	probeStop func()
+
+	latency  *latencyRing
+	history  *historyRing
+	events   chan CircuitEvent
+	velocity *velocityRing
+	fair     fifoLock
 }
 
-// New creates a Circuit with the given options.
+// New creates a Circuit with the given options. Invalid settings, such as
+// a threshold below 1, fall back to their defaults; use NewWithError or
+// MustNew to reject them instead.
 func New(name string, opts ...Option) *Circuit {
-	cfg := config{
-		failureThreshold: DefaultFailureThreshold,
-		successThreshold: DefaultSuccessThreshold,
-		openDuration:     DefaultOpenDuration,
-		halfOpenRequests: DefaultHalfOpenRequests,
-		condition:        defaultCondition,
-		clock:            realClock{},
+	cfg := newConfig(opts)
+	cfg.normalize()
+	return newCircuit(name, cfg)
+}
+
+// NewWithError is like New but returns a *ConfigError if the options
+// produce an invalid configuration.
+func NewWithError(name string, opts ...Option) (*Circuit, error) {
+	cfg := newConfig(opts)
+	if err := cfg.validate(); err != nil {
+		return nil, err
 	}
-	for _, opt := range opts {
-		opt(&cfg)
+	// A valid config still needs its unset values resolved, such as a
+	// zero opened-at for WithInitialState.
+	cfg.normalize()
+	return newCircuit(name, cfg), nil
+}
+
+// MustNew is like NewWithError but panics with the *ConfigError. It
+// simplifies safe initialization of global variables.
+func MustNew(name string, opts ...Option) *Circuit {
+	c, err := NewWithError(name, opts...)
+	if err != nil {
+		panic(err)
 	}
-	return &Circuit{
-		name:  name,
-		cfg:   cfg,
-		state: Closed,
+	return c
+}
+
+// NewConditional is like New for a circuit gated by a feature flag. Each
+// call first checks enabled, without holding the circuit's lock. While it
+// returns false, Do and Allow pass calls straight through: no state is
+// read or changed, no hooks fire, and nothing is counted. Calls are still
+// rejected while draining, tracked by InFlight and Drain, and subject to
+// WithReentrancyCheck and WithRecoverPanics. While it returns
+// true, the circuit behaves as usual, picking up from the state it had
+// when the flag was last on. A nil enabled is always on. State, Enabled,
+// and the other accessors describe the circuit regardless of the flag.
+func NewConditional(name string, enabled func() bool, opts ...Option) *Circuit {
+	c := New(name, opts...)
+	c.gate = enabled
+	return c
+}
+
+func newCircuit(name string, cfg config) *Circuit {
+	c := &Circuit{
+		name:         name,
+		fullName:     qualifiedName(cfg.namespace, name),
+		id:           cfg.id,
+		cfg:          cfg,
+		state:        cfg.initialState,
+		openedAt:     cfg.initialOpenedAt,
+		downSince:    cfg.initialOpenedAt,
+		openFor:      cfg.openDuration,
+		createdAt:    cfg.clock.Now(),
+		halfOpenedAt: cfg.clock.Now(),
+		warmupLeft:   cfg.warmupCalls,
+		changed:      make(chan struct{}),
+		drained:      make(chan struct{}),
+		stop:         make(chan struct{}),
+	}
+	if c.id == "" {
+		c.id = newCircuitID()
+	}
+	// A circuit that starts half-open has no opened-at time; date its
+	// downtime from creation.
+	if c.state != Closed && c.downSince.IsZero() {
+		c.downSince = c.createdAt
+	}
+	c.stateHint.Store(int32(c.state))
+	c.publishOpenUntil()
+	c.disabled.Store(cfg.disabled)
+	if cfg.velocityN > 0 {
+		c.velocity = newVelocityRing(cfg.velocityN)
+	}
+	if cfg.latencyWindow > 0 {
+		c.latency = newLatencyRing(cfg.latencyWindow)
+	}
+	if cfg.history > 0 {
+		c.history = newHistoryRing(cfg.history)
 	}
+	if cfg.eventBuffer > 0 {
+		c.events = make(chan CircuitEvent, cfg.eventBuffer)
+		c.cfg.observers = append(slices.Clip(cfg.observers), eventQueue(c.events))
+	}
+	if c.state != Closed {
+		c.openErr = c.newOpenError(nil)
+	}
+	c.armAutoReset()
+	if cfg.budget != nil {
+		cfg.budget.add(c)
+	}
+	if cfg.probe != nil && cfg.probeInterval > 0 {
+		c.probeMu.Lock()
+		c.armProbe()
+		c.probeMu.Unlock()
+	}
+	return c
+}
+
+// Result describes the outcome of a call made through DoResult.
+type Result struct {
+	// Err is the error Do would have returned.
+	Err error
+	// ReopenedCircuit reports whether this call was a half-open trial
+	// whose failure sent the circuit back to Open.
+	ReopenedCircuit bool
 }
 
 // Do executes fn with circuit breaker protection.
 func (c *Circuit) Do(ctx context.Context, fn Func) error {
-	state, err := c.allow()
+	return c.do(ctx, fn, c.isFailure).Err
+}
+
+// DoResult executes fn like Do, additionally reporting whether the call
+// reopened the circuit. This lets callers tell a failed half-open trial
+// apart from an ordinary failure while Closed.
+func (c *Circuit) DoResult(ctx context.Context, fn Func) Result {
+	return c.do(ctx, fn, c.isFailure)
+}
+
+// DoTimed executes fn like Do and also returns how long fn ran, measured
+// with the circuit's clock. Admission and recording are not included, and
+// the duration is zero if the circuit rejected the call.
+func (c *Circuit) DoTimed(ctx context.Context, fn Func) (time.Duration, error) {
+	var elapsed time.Duration
+	err := c.Do(ctx, func(ctx context.Context) error {
+		start := c.cfg.clock.Now()
+		defer func() {
+			elapsed = c.cfg.clock.Now().Sub(start)
+		}()
+		return fn(ctx)
+	})
+	return elapsed, err
+}
+
+// do runs fn through the circuit, using classify to decide whether its
+// error counts as a failure.
+func (c *Circuit) do(ctx context.Context, fn Func, classify classifier) Result {
+	if c.shutdown.Load() {
+		return Result{Err: ErrShutdown}
+	}
+	if c.cfg.reentrancyCheck {
+		if entered(ctx, c) {
+			return Result{Err: ErrReentrant}
+		}
+		ctx = enter(ctx, c)
+	}
+
+	c.inFlight.Add(1)
+	defer c.done()
+
+	if c.draining.Load() {
+		return Result{Err: ErrDraining}
+	}
+	if c.gate != nil && !c.gate() {
+		return Result{Err: c.passthrough(ctx, fn)}
+	}
+	if c.disabled.Load() {
+		c.totalCalls.Add(1)
+		return Result{Err: fn(ctx)}
+	}
+
+	force := forced(ctx)
+	state, probe, _, err := c.allow()
+	if err != nil && c.cfg.priorityQueue && state == HalfOpen && c.displace(callPriority(ctx)) {
+		err = nil
+	}
+	if err != nil && force&forceAllow != 0 {
+		c.totalCalls.Add(1)
+		if force&forceFail != 0 {
+			return Result{Err: ErrForcedFailure}
+		}
+		return Result{Err: fn(ctx)}
+	}
 	if err != nil {
-		if c.cfg.onReject != nil {
-			c.cfg.onReject(c.name)
+		c.reject(state)
+		return Result{Err: err}
+	}
+
+	timed := c.timed()
+	var start time.Time
+	if timed {
+		start = c.cfg.clock.Now()
+	}
+
+	// A panic that escapes fn skips record, so give back the half-open
+	// slot allow took or the circuit could never leave HalfOpen.
+	completed := false
+	var t *trial
+	if state == HalfOpen {
+		defer func() {
+			if !completed && !c.endTrial(t) {
+				c.releaseHalfOpen()
+			}
+		}()
+	}
+
+	// The WithProbeFunc probe stands in for this call's trial. If it
+	// closes the circuit, fn runs as an ordinary closed-state call.
+	if probe {
+		reopened, err := c.runProbeFunc(ctx)
+		completed = true
+		if err != nil {
+			return Result{Err: err, ReopenedCircuit: reopened}
+		}
+		state = Closed
+	}
+
+	if state == HalfOpen && c.cfg.halfOpenTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.cfg.halfOpenTimeout)
+		defer cancel()
+	}
+
+	if state == HalfOpen && c.cfg.halfOpenProbe != nil && !c.cfg.dryRun {
+		if reopened, err := c.runProbe(ctx); err != nil {
+			completed = true
+			return Result{Err: err, ReopenedCircuit: reopened}
 		}
-		return err
 	}
 
-	fnErr := fn(ctx)
+	// Under WithPriorityQueue, a higher-priority call may take this
+	// trial's slot while fn runs. fn's context is then cancelled and the
+	// call is rejected instead of recorded.
+	if state == HalfOpen && c.cfg.priorityQueue {
+		ctx, t = c.startTrial(ctx)
+	}
+
+	c.totalCalls.Add(1)
+	var fnErr error
+	if force&forceFail != 0 {
+		fnErr = ErrForcedFailure
+	} else {
+		fnErr = c.call(ctx, fn)
+	}
+	// Leaving completed unset makes the deferred release give back a
+	// half-open slot the unrecorded call held.
+	if u, ok := fnErr.(unrecordedError); ok {
+		return Result{Err: u.err}
+	}
+	completed = true
+	if c.endTrial(t) {
+		return c.rejectDisplaced(state)
+	}
 
-	c.record(fnErr)
+	opened, reopened := c.report(ctx, state, start, timed, fnErr, classify)
 
-	if c.cfg.onCall != nil {
-		c.cfg.onCall(c.name, state, fnErr)
+	if pe, ok := fnErr.(*PanicError); ok && c.cfg.repanic {
+		panic(pe.Value)
+	}
+	if fnErr != nil && c.cfg.richErrors {
+		fnErr = &CircuitError{Err: fnErr, Name: c.fullName, ID: c.id, State: state, IsTrip: opened}
 	}
+	return Result{Err: fnErr, ReopenedCircuit: reopened}
+}
 
-	return fnErr
+// reject counts a call that allow rejected in state.
+func (c *Circuit) reject(state State) {
+	c.totalRejections.Add(1)
+	c.emit(CircuitEvent{
+		Kind:   EventReject,
+		Name:   c.fullName,
+		At:     c.cfg.clock.Now(),
+		State:  state,
+		Reason: rejectReason(state),
+	})
 }
 
-// State returns the current state.
+// timed reports whether anything needs the time a call takes.
+func (c *Circuit) timed() bool {
+	return len(c.cfg.observers) > 0 || c.cfg.outcomeSink != nil || c.latency != nil || c.history != nil
+}
+
+// report records the outcome of a call admitted in state and, if timed,
+// reports its latency and outcome from start. It returns what record does
+// about opening the circuit.
+func (c *Circuit) report(ctx context.Context, state State, start time.Time, timed bool, err error, classify classifier) (opened, reopened bool) {
+	exhausted, opened, reopened := c.record(ctx, err, classify)
+	if exhausted {
+		c.cfg.budget.trip()
+	}
+
+	if timed {
+		end := c.cfg.clock.Now()
+		if c.latency != nil {
+			c.latency.add(end.Sub(start))
+		}
+		if c.history != nil {
+			c.history.add(HistoryEntry{
+				At:       end,
+				State:    state,
+				Err:      errString(err),
+				Duration: end.Sub(start),
+			})
+		}
+		if c.cfg.outcomeSink != nil {
+			c.cfg.outcomeSink(Outcome{
+				At:       end,
+				Name:     c.fullName,
+				State:    state,
+				Err:      err,
+				Duration: end.Sub(start),
+			})
+		}
+		if c.cfg.samplingRate >= 1 || c.cfg.rand() < c.cfg.samplingRate {
+			c.emit(CircuitEvent{
+				Kind:     EventCall,
+				Name:     c.fullName,
+				At:       end,
+				State:    state,
+				Err:      err,
+				Duration: end.Sub(start),
+			})
+		}
+	}
+	return opened, reopened
+}
+
+// State returns the effective state. It does not change the circuit: an
+// open circuit whose open duration has elapsed is reported as HalfOpen,
+// but the transition, and its OnStateChange, happens on the next call.
+// A disabled circuit always reports Closed.
 func (c *Circuit) State() State {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.currentState()
+	// Closed never changes lazily, so it can be reported without the lock.
+	if State(c.stateHint.Load()) == Closed {
+		return Closed
+	}
+	c.rlock()
+	defer c.runlock()
+	return c.readState()
+}
+
+// Tripped reports whether the circuit is open and rejecting calls. It reads
+// atomic copies of the state instead of taking the lock, so it is cheap
+// enough to call before every attempt on a hot path. The tradeoff is that
+// it may briefly disagree with a transition happening concurrently. A
+// half-open circuit, which admits trial calls, is not tripped. Neither is a
+// disabled or dry-run circuit.
+func (c *Circuit) Tripped() bool {
+	if State(c.stateHint.Load()) != Open || c.disabled.Load() || c.cfg.dryRun {
+		return false
+	}
+	return c.cfg.clock.Now().UnixNano() < c.openUntil.Load()
+}
+
+// Wait blocks until the circuit is Closed or ctx is done.
+func (c *Circuit) Wait(ctx context.Context) error {
+	return c.WaitForState(ctx, Closed)
+}
+
+// WaitForState blocks until the circuit is in state s or ctx is done.
+// It returns immediately if the circuit is already in s.
+func (c *Circuit) WaitForState(ctx context.Context, s State) error {
+	for {
+		c.mu.Lock()
+		cur := c.readState()
+		changed := c.changed
+		var timeout time.Duration
+		if cur == Open {
+			timeout = c.openFor - c.cfg.clock.Now().Sub(c.openedAt)
+		}
+		c.mu.Unlock()
+
+		if cur == s {
+			return nil
+		}
+
+		// An open circuit moves to half-open lazily, so wake up when the
+		// open duration elapses on the circuit's clock to re-evaluate.
+		var expired <-chan time.Time
+		stop := func() {}
+		if cur == Open {
+			expired, stop = after(c.cfg.clock, timeout)
+		}
+
+		select {
+		case <-changed:
+		case <-expired:
+		case <-ctx.Done():
+		}
+		stop()
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// IsClosed reports whether the circuit is currently closed.
+func (c *Circuit) IsClosed() bool {
+	return c.State() == Closed
+}
+
+// IsOpen reports whether the circuit is currently open.
+// Unlike the package-level IsOpen, this inspects the circuit's state
+// rather than an error.
+func (c *Circuit) IsOpen() bool {
+	return c.State() == Open
+}
+
+// IsHalfOpen reports whether the circuit is currently half-open.
+func (c *Circuit) IsHalfOpen() bool {
+	return c.State() == HalfOpen
 }
 
 // Reset manually resets the circuit to closed state.
+// It also restarts the WithWarmupCalls period.
+//
+// Deprecated: Use ResetAndReport, which also reports whether the state
+// changed. Reset will be removed in the next major version.
 func (c *Circuit) Reset() {
+	c.ResetAndReport()
+}
+
+// ResetAndReport manually resets the circuit to closed state and reports
+// whether that changed its state, which is false if it was already
+// Closed. It also restarts the WithWarmupCalls period.
+func (c *Circuit) ResetAndReport() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	changed := c.state != Closed
+	c.reset()
+	return changed
+}
+
+func (c *Circuit) reset() {
+	c.setState(Closed)
+	c.warmupLeft = c.cfg.warmupCalls
+}
+
+// ClearCounts zeroes the failure, success, and half-open counters without
+// changing state. Unlike Reset, an open circuit stays open and no state
+// change is reported.
+func (c *Circuit) ClearCounts() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures = 0
+	c.successes = 0
+	c.halfOpenCnt = 0
+	if c.velocity != nil {
+		c.velocity.reset()
+	}
+	if c.state == Closed {
+		c.lastErr = nil
+	}
+}
+
+// InFlight returns the number of calls currently executing.
+func (c *Circuit) InFlight() int {
+	return int(c.inFlight.Load())
+}
+
+// Drain stops the circuit from accepting new calls and blocks until
+// in-flight calls complete, ctx is done, or the WithGracefulDrain timeout
+// elapses. New calls are rejected with ErrDraining. In-flight calls made
+// through Do are always tracked; WithGracefulDrain only adds a timeout, and
+// without it Drain waits until the calls complete or ctx is done.
+func (c *Circuit) Drain(ctx context.Context) error {
+	c.draining.Store(true)
+	if c.inFlight.Load() == 0 {
+		c.signalDrained()
+	}
+
+	if c.cfg.drainTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.cfg.drainTimeout)
+		defer cancel()
+	}
+
+	select {
+	case <-c.drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops any background work started by the circuit, such as active
+// probing, leaves any WithBudget budget, and makes the circuit inert:
+// subsequent calls to Do return ErrShutdown without executing. It is safe
+// to call more than once.
+func (c *Circuit) Close() error {
+	c.shutdown.Store(true)
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+	c.probeMu.Lock()
+	if c.probeStop != nil {
+		c.probeStop()
+		c.probeStop = nil
+	}
+	c.probeMu.Unlock()
+	c.probeDone.Wait()
+	if c.cfg.budget != nil {
+		c.cfg.budget.Remove(c)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.disarmAutoReset()
+	return nil
+}
+
+// Disable puts the circuit in passthrough mode: Do calls fn directly,
+// no state is tracked, and no hooks fire. A circuit that is not closed is
+// closed first, which is reported as a state change as Reset reports it,
+// and any accumulated counts are discarded. Unlike Reset, tracking does
+// not resume until Enable.
+func (c *Circuit) Disable() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.setState(Closed)
+	c.disabled.Store(true)
+	c.failures = 0
+	c.successes = 0
+	c.halfOpenCnt = 0
+	c.ramping = false
+	if c.velocity != nil {
+		c.velocity.reset()
+	}
+}
+
+// Enable resumes circuit breaking after Disable or WithDisabled.
+// The circuit starts closed with zero counts.
+func (c *Circuit) Enable() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.disabled.Store(false)
 }
 
-// Name returns the circuit name.
+// Enabled reports whether the circuit is breaking calls, that is, not in
+// the passthrough mode set by Disable or WithDisabled.
+func (c *Circuit) Enabled() bool {
+	return !c.disabled.Load()
+}
+
+// Name returns the circuit name, without any WithNamespace prefix.
 func (c *Circuit) Name() string {
 	return c.name
 }
 
-// Counts returns the current failure and success counts.
-func (c *Circuit) Counts() (failures, successes int) {
+// FullName returns the circuit name qualified by its WithNamespace
+// namespace, as in "billing:database". Hooks, observers, errors,
+// snapshots, and the Registry identify the circuit by this name. Without a
+// namespace it is the same as Name.
+func (c *Circuit) FullName() string {
+	return c.fullName
+}
+
+// ID returns the circuit's WithCircuitID identifier, or the random UUID
+// generated for it by New. Unlike FullName, it tells apart circuit
+// instances with the same name, such as one per process.
+func (c *Circuit) ID() string {
+	return c.id
+}
+
+// newCircuitID returns a random (version 4) UUID.
+func newCircuitID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// qualifiedName returns name prefixed with namespace, if any.
+func qualifiedName(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + ":" + name
+}
+
+// Err returns the most recent error counted as a failure. While the
+// circuit is Open or HalfOpen, that is the failure which opened it or the
+// last failed trial call. It returns nil when the circuit is Closed with no
+// failures. The error is dropped whenever the circuit closes, including
+// through Reset, so a large error is not retained once it stops mattering.
+// Snapshot.LastErr reports its message.
+func (c *Circuit) Err() error {
+	c.rlock()
+	defer c.runlock()
+	return c.lastErr
+}
+
+// Stats holds a circuit's counters.
+type Stats struct {
+	// Failures and Successes are the current counts toward the failure and
+	// success thresholds. They reset on state changes.
+	Failures  int
+	Successes int
+
+	// HalfOpenProbes is the number of trial calls admitted in the current
+	// half-open period.
+	HalfOpenProbes int
+
+	// TotalCalls and TotalRejections count the calls executed and the
+	// calls rejected over the circuit's lifetime. They never reset.
+	TotalCalls      int64
+	TotalRejections int64
+}
+
+// Counts returns the circuit's counters. A disabled circuit reports zero
+// current counts but keeps its lifetime totals.
+func (c *Circuit) Counts() Stats {
+	c.rlock()
+	defer c.runlock()
+	stats := Stats{
+		TotalCalls:      c.totalCalls.Load(),
+		TotalRejections: c.totalRejections.Load(),
+	}
+	if !c.disabled.Load() {
+		stats.Failures = c.failures
+		stats.Successes = c.successes
+		stats.HalfOpenProbes = c.halfOpenCnt
+	}
+	return stats
+}
+
+// Counts2 returns the current failure and success counts.
+//
+// Deprecated: Use Counts, whose Stats names each count.
+func (c *Circuit) Counts2() (failures, successes int) {
+	stats := c.Counts()
+	return stats.Failures, stats.Successes
+}
+
+// ForceOpen opens the circuit from any state, rejecting calls for the open
+// duration as if it had tripped. An open circuit is left as is. It has no
+// effect on a disabled circuit.
+func (c *Circuit) ForceOpen() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	return c.failures, c.successes
+	if !c.disabled.Load() && c.currentState() != Open {
+		c.open(nil)
+	}
 }
 
-func (c *Circuit) allow() (State, error) {
+// trip opens the circuit if it is closed and not warming up.
+func (c *Circuit) trip() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	if !c.disabled.Load() && c.currentState() == Closed && !c.warmingUp() {
+		c.open(nil)
+	}
+}
+
+// armProbe schedules the next active probe on the circuit's clock. It must
+// be called with probeMu held.
+func (c *Circuit) armProbe() {
+	c.probeStop = afterFunc(c.cfg.clock, c.cfg.probeInterval, c.probeFired)
+}
 
+// probeFired runs a due active probe and schedules the next one, unless
+// the circuit has been closed.
+func (c *Circuit) probeFired() {
+	c.probeMu.Lock()
+	if c.shutdown.Load() {
+		c.probeMu.Unlock()
+		return
+	}
+	c.probeDone.Add(1)
+	c.probeMu.Unlock()
+	defer c.probeDone.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-c.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	c.activeProbe(ctx)
+
+	c.probeMu.Lock()
+	defer c.probeMu.Unlock()
+	if !c.shutdown.Load() {
+		c.armProbe()
+	}
+}
+
+func (c *Circuit) activeProbe(ctx context.Context) {
+	c.mu.Lock()
 	state := c.currentState()
+	c.mu.Unlock()
+	if state == Closed || c.disabled.Load() {
+		return
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, c.cfg.probeInterval)
+	defer cancel()
+	err := c.cfg.probe(probeCtx)
+	if ctx.Err() != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state != state {
+		return
+	}
+	if c.isFailure(probeCtx, err) {
+		c.openedAt = c.cfg.clock.Now()
+		c.open(err)
+		return
+	}
+	if state == Open {
+		c.setState(HalfOpen)
+	}
+}
+
+// passthrough runs fn for a call that NewConditional's flag lets bypass
+// the state machine. Panics are still handled per WithRecoverPanics.
+func (c *Circuit) passthrough(ctx context.Context, fn Func) error {
+	err := c.call(ctx, fn)
+	if pe, ok := err.(*PanicError); ok && c.cfg.repanic {
+		panic(pe.Value)
+	}
+	return err
+}
+
+// call runs fn, converting a panic into a *PanicError under
+// WithRecoverPanics.
+func (c *Circuit) call(ctx context.Context, fn Func) (err error) {
+	if !c.cfg.recoverPanics {
+		return fn(ctx)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn(ctx)
+}
+
+// runProbe runs the WithProbe health check ahead of a half-open call. If
+// the check fails, it is recorded in place of the call, which is rejected
+// with the resulting open error.
+func (c *Circuit) runProbe(ctx context.Context) (reopened bool, err error) {
+	probeErr := c.call(ctx, c.cfg.halfOpenProbe)
+	if !c.isFailure(ctx, probeErr) {
+		return false, nil
+	}
+	return c.rejectProbe(ctx, probeErr)
+}
+
+// runProbeFunc runs the WithProbeFunc probe as the first trial of a
+// half-open period. Success closes the circuit outright. Failure is
+// recorded and the call is rejected with the resulting open error.
+func (c *Circuit) runProbeFunc(ctx context.Context) (reopened bool, err error) {
+	if c.cfg.halfOpenTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.cfg.halfOpenTimeout)
+		defer cancel()
+	}
+	probeErr := c.call(ctx, c.cfg.probeFunc)
+	if c.isFailure(ctx, probeErr) {
+		return c.rejectProbe(ctx, probeErr)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state == HalfOpen {
+		c.closeRecovered()
+	}
+	return false, nil
+}
+
+// rejectDisplaced rejects a half-open trial whose slot was taken by a
+// higher-priority call under WithPriorityQueue.
+func (c *Circuit) rejectDisplaced(state State) Result {
+	c.totalRejections.Add(1)
+
+	c.mu.Lock()
+	err := c.rejection()
+	c.mu.Unlock()
+
+	c.emit(CircuitEvent{
+		Kind:   EventReject,
+		Name:   c.fullName,
+		At:     c.cfg.clock.Now(),
+		State:  state,
+		Reason: ReasonHalfOpenBudget,
+	})
+	return Result{Err: err}
+}
+
+// rejectProbe records a failed probe in place of the call it preceded and
+// returns the error that rejects the call.
+func (c *Circuit) rejectProbe(ctx context.Context, probeErr error) (reopened bool, err error) {
+	_, _, reopened = c.record(ctx, probeErr, c.isFailure)
+	c.totalRejections.Add(1)
+
+	c.mu.Lock()
+	err = c.rejection()
+	c.mu.Unlock()
+
+	c.emit(CircuitEvent{
+		Kind:   EventReject,
+		Name:   c.fullName,
+		At:     c.cfg.clock.Now(),
+		State:  HalfOpen,
+		Reason: ReasonOpen,
+	})
+	return reopened, err
+}
+
+// releaseHalfOpen returns a half-open slot taken by allow for a call that
+// never recorded an outcome.
+func (c *Circuit) releaseHalfOpen() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state == HalfOpen && c.halfOpenCnt > 0 {
+		c.halfOpenCnt--
+	}
+}
+
+// releaseTrial gives back a trial slot taken in half-open period gen, if
+// the circuit is still in that period.
+func (c *Circuit) releaseTrial(gen uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.halfOpenGen == gen && c.state == HalfOpen && c.halfOpenCnt > 0 {
+		c.halfOpenCnt--
+	}
+}
+
+func (c *Circuit) done() {
+	if c.inFlight.Add(-1) == 0 && c.draining.Load() {
+		c.signalDrained()
+	}
+}
+
+func (c *Circuit) signalDrained() {
+	c.drainOnce.Do(func() {
+		close(c.drained)
+	})
+}
+
+// allow decides whether a call may proceed. probe reports that the call
+// takes the first slot of a half-open period and WithProbeFunc should run
+// in its place. gen is the half-open period a half-open call was admitted
+// in, for releaseTrial.
+func (c *Circuit) allow() (state State, probe bool, gen uint64, err error) {
+	// A closed circuit admits every call unless it limits calls, which
+	// needs the lock.
+	if State(c.stateHint.Load()) == Closed && c.cfg.callLimit == 0 && c.cfg.recoveryRamp == 0 {
+		return Closed, false, 0, nil
+	}
+
+	// WithFairHalfOpen queues callers of a circuit that is not closed so
+	// half-open slots go out in arrival order.
+	if c.cfg.fairHalfOpen && State(c.stateHint.Load()) != Closed {
+		c.fair.lock()
+		defer c.fair.unlock()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state = c.currentState()
 	switch state {
+	case Closed:
+		if c.ramping && !c.cfg.dryRun && c.inFlight.Load() > c.rampLimit() {
+			return state, false, 0, ErrOpen
+		}
+		if c.cfg.callLimit > 0 {
+			c.closedCalls++
+			if c.closedCalls >= c.cfg.callLimit {
+				c.open(ErrCallLimitExceeded)
+			}
+		}
 	case Open:
-		return state, ErrOpen
+		if c.cfg.dryRun {
+			return state, false, 0, nil
+		}
+		return state, false, 0, c.rejection()
 	case HalfOpen:
+		if c.cfg.dryRun {
+			c.halfOpenCnt++
+			break
+		}
+		if c.cfg.halfOpenRatio > 0 && c.cfg.rand() >= c.cfg.halfOpenRatio {
+			return state, false, 0, c.rejection()
+		}
 		if c.halfOpenCnt >= c.cfg.halfOpenRequests {
-			return state, ErrOpen
+			return state, false, 0, c.rejection()
+		}
+		if c.cfg.halfOpenInterval > 0 {
+			now := c.cfg.clock.Now()
+			if c.halfOpenCnt > 0 && now.Sub(c.lastProbeAt) < c.cfg.halfOpenInterval {
+				return state, false, 0, c.rejection()
+			}
+			c.lastProbeAt = now
 		}
 		c.halfOpenCnt++
+		probe = c.cfg.probeFunc != nil && c.halfOpenCnt == 1
 	}
-	return state, nil
+	return state, probe, c.halfOpenGen, nil
 }
 
-func (c *Circuit) record(err error) {
+// record updates counts and state for the outcome of a call, using
+// classify to decide whether err is a failure. It reports whether the call
+// exhausted the circuit's shared Budget, whether it opened the circuit,
+// and whether that sent a half-open circuit back to Open.
+func (c *Circuit) record(ctx context.Context, err error, classify classifier) (exhausted, opened, reopened bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	isFailure := c.cfg.condition(err)
+	if err != nil && c.cfg.tripOn != nil && c.cfg.tripOn(err) {
+		opened, reopened = c.tripNow(err)
+		return false, opened, reopened
+	}
 
-	switch c.currentState() {
+	state := c.currentState()
+	// Warm-up only discards closed-state outcomes: a half-open trial
+	// must be recorded or its slot would never be given back.
+	if state == Closed && c.warmupLeft > 0 {
+		c.warmupLeft--
+		return false, false, false
+	}
+
+	isFailure := classify(ctx, err)
+	weight := 1
+	if isFailure && c.cfg.failureWeight != nil {
+		weight = c.cfg.failureWeight(err)
+		if weight <= 0 {
+			// An ignored failure says nothing about recovery, so give
+			// its half-open slot to another trial.
+			if state == HalfOpen && c.halfOpenCnt > 0 {
+				c.halfOpenCnt--
+			}
+			return false, false, false
+		}
+	}
+
+	switch state {
 	case Closed:
 		if isFailure {
-			c.failures++
-			if c.failures >= c.cfg.failureThreshold {
-				c.setState(Open)
+			now := c.cfg.clock.Now()
+			if c.cfg.window > 0 && c.failures > 0 && now.Sub(c.lastFailureAt) > c.cfg.window {
+				c.failures = 0
+			}
+			c.lastFailureAt = now
+			c.lastErr = err
+			c.failures += weight
+			spiking := c.velocity != nil && c.velocity.add(now, c.cfg.velocityWindow)
+			if c.cfg.budget != nil {
+				exhausted = c.cfg.budget.fail(c)
+			}
+			tripped := c.failures >= c.failureThreshold() && !c.settling(now)
+			if (tripped || spiking || exhausted) && !c.warmingUp() {
+				c.open(err)
+				opened = true
 			}
 		} else {
 			c.failures = 0
+			c.lastErr = nil
+			if c.cfg.budget != nil {
+				c.cfg.budget.succeed(c)
+			}
 		}
 
 	case HalfOpen:
+		c.emitProbe(err, !isFailure)
 		if isFailure {
-			c.setState(Open)
+			c.lastErr = err
+			c.open(err)
+			opened, reopened = true, true
 		} else {
 			c.successes++
 			if c.successes >= c.cfg.successThreshold {
-				c.setState(Closed)
+				c.closeRecovered()
 			}
 		}
 	}
+	return exhausted, opened, reopened
+}
+
+// tripNow opens the circuit for a WithTripImmediatelyOn error and reports
+// whether it did, and whether it reopened a half-open circuit. It must be
+// called with c.mu held.
+func (c *Circuit) tripNow(err error) (opened, reopened bool) {
+	state := c.currentState()
+	if state == Open {
+		return false, false
+	}
+	if state == HalfOpen {
+		c.emitProbe(err, false)
+	}
+	c.lastErr = err
+	c.lastFailureAt = c.cfg.clock.Now()
+	c.open(err)
+	return true, state == HalfOpen
+}
+
+// emitProbe reports the outcome of a half-open trial.
+func (c *Circuit) emitProbe(err error, success bool) {
+	c.emit(CircuitEvent{
+		Kind:    EventProbe,
+		Name:    c.fullName,
+		At:      c.cfg.clock.Now(),
+		State:   HalfOpen,
+		Err:     err,
+		Success: success,
+	})
+}
+
+// failureThreshold returns the threshold in effect. It must be called with
+// c.mu held.
+func (c *Circuit) failureThreshold() int {
+	if c.cfg.thresholdFunc != nil {
+		if n := c.cfg.thresholdFunc(); n >= 1 {
+			return n
+		}
+	}
+	return c.cfg.failureThreshold
+}
+
+// settling reports whether the circuit closed less than
+// WithMinClosedDuration ago.
+func (c *Circuit) settling(now time.Time) bool {
+	return c.cfg.minClosed > 0 && !c.closedAt.IsZero() && now.Sub(c.closedAt) < c.cfg.minClosed
+}
+
+func (c *Circuit) warmingUp() bool {
+	return c.cfg.clock.Now().Sub(c.createdAt) < c.cfg.warmup
+}
+
+// closeRecovered closes a half-open circuit whose trial calls succeeded and
+// reports the recovery. Must be called with mu held.
+func (c *Circuit) closeRecovered() {
+	c.setState(Closed)
+	now := c.cfg.clock.Now()
+	c.emit(CircuitEvent{
+		Kind:     EventRecover,
+		Name:     c.fullName,
+		At:       now,
+		Duration: now.Sub(c.downSince),
+	})
+}
+
+// classifier decides whether a call's error counts as a failure.
+type classifier func(ctx context.Context, err error) bool
+
+// isFailure reports whether err counts as a failure. ErrForcedFailure always
+// does. Otherwise a context condition
+// takes precedence over the error-only condition, which takes precedence
+// over the default.
+func (c *Circuit) isFailure(ctx context.Context, err error) bool {
+	switch {
+	case err == ErrForcedFailure:
+		return true
+	case c.cfg.contextCondition != nil:
+		return c.cfg.contextCondition(ctx, err)
+	case c.cfg.condition != nil:
+		return c.cfg.condition(err)
+	default:
+		return c.defaultCondition(ctx, err)
+	}
+}
+
+// rlock locks the circuit for reading: shared under WithRWMutex,
+// exclusive otherwise.
+func (c *Circuit) rlock() {
+	if c.cfg.rwMutex {
+		c.mu.RLock()
+	} else {
+		c.mu.Lock()
+	}
+}
+
+func (c *Circuit) runlock() {
+	if c.cfg.rwMutex {
+		c.mu.RUnlock()
+	} else {
+		c.mu.Unlock()
+	}
+}
+
+// readState returns the state as reported to callers, under rlock. It
+// reports a due timeout transition without making it; the next call
+// through the circuit does, so reading state never fires OnStateChange.
+// A disabled circuit always reports Closed.
+func (c *Circuit) readState() State {
+	if c.disabled.Load() {
+		return Closed
+	}
+	if c.state == Open && c.cfg.clock.Now().Sub(c.openedAt) >= c.openFor {
+		return HalfOpen
+	}
+	if c.halfOpenExpired() {
+		return Open
+	}
+	return c.state
 }
 
+// currentState makes any due timeout transition and returns the resulting
+// state. It is for the admission and recording paths; getters use
+// readState.
 func (c *Circuit) currentState() State {
-	if c.state == Open && c.cfg.clock.Now().Sub(c.openedAt) >= c.cfg.openDuration {
+	switch {
+	case c.state == Open && c.cfg.clock.Now().Sub(c.openedAt) >= c.openFor:
 		c.setState(HalfOpen)
+	case c.halfOpenExpired():
+		c.open(nil)
 	}
 	return c.state
 }
 
+// halfOpenExpired reports whether the circuit has been half-open longer
+// than WithMaxHalfOpenDuration allows.
+func (c *Circuit) halfOpenExpired() bool {
+	return c.state == HalfOpen && c.cfg.maxHalfOpen > 0 &&
+		c.cfg.clock.Now().Sub(c.halfOpenedAt) >= c.cfg.maxHalfOpen
+}
+
 func (c *Circuit) setState(to State) {
 	if c.state == to {
 		return
 	}
 	from := c.state
 	c.state = to
+	c.stateHint.Store(int32(to))
+	c.notify()
 
 	c.failures = 0
 	c.successes = 0
 	c.halfOpenCnt = 0
+	c.trials.clear()
+	if c.velocity != nil {
+		c.velocity.reset()
+	}
+	if from == Closed {
+		c.downSince = c.cfg.clock.Now()
+	}
 
-	if to == Open {
+	switch to {
+	case Open:
 		c.openedAt = c.cfg.clock.Now()
+		c.openFor = c.cfg.openDuration + c.cooldown(from)
+	case HalfOpen:
+		c.halfOpenGen++
+		// The lazy transition may be made late; date it from when the
+		// Open period ended.
+		c.halfOpenAt = c.cfg.clock.Now()
+		c.halfOpenedAt = c.halfOpenAt
+		if due := c.openedAt.Add(c.openFor); from == Open && due.Before(c.halfOpenAt) {
+			c.halfOpenAt = due
+		}
+	case Closed:
+		c.openErr = nil
+		c.lastErr = nil
+		c.flaps = 0
+		c.closedCalls = 0
+		c.closedAt = c.cfg.clock.Now()
+	}
+	c.ramping = from == HalfOpen && to == Closed && c.cfg.recoveryRamp > 0
+	if c.ramping {
+		c.recoveredAt = c.cfg.clock.Now()
+	}
+	c.armAutoReset()
+
+	c.emit(CircuitEvent{
+		Kind: EventStateChange,
+		Name: c.fullName,
+		At:   c.cfg.clock.Now(),
+		From: from,
+		To:   to,
+	})
+}
+
+// cooldown returns the WithCooldown extension for an Open period entered
+// from state from. A re-trip from half-open extends the period by the
+// cooldown; each further re-trip within the cooldown of entering half-open
+// extends it by another.
+func (c *Circuit) cooldown(from State) time.Duration {
+	if c.cfg.cooldown <= 0 || from != HalfOpen {
+		c.flaps = 0
+		return 0
+	}
+	if c.flaps > 0 && c.cfg.clock.Now().Sub(c.halfOpenAt) <= c.cfg.cooldown {
+		c.flaps++
+	} else {
+		c.flaps = 1
+	}
+	return time.Duration(c.flaps) * c.cfg.cooldown
+}
+
+// open opens the circuit because of cause, which later rejections wrap. A
+// cause wrapped with OpenFor overrides the open duration.
+func (c *Circuit) open(cause error) {
+	c.setState(Open)
+	var of *openForError
+	if errors.As(cause, &of) && of.d > 0 {
+		c.openFor = of.d
+	}
+	c.publishOpenUntil()
+	c.openErr = c.newOpenError(cause)
+}
+
+// publishOpenUntil records when the Open period ends for Tripped. Must be
+// called with mu held whenever openedAt or openFor changes.
+func (c *Circuit) publishOpenUntil() {
+	c.openUntil.Store(c.openedAt.Add(c.openFor).UnixNano())
+}
+
+func (c *Circuit) newOpenError(cause error) *OpenError {
+	return &OpenError{
+		Name:         c.fullName,
+		ID:           c.id,
+		OpenedAt:     c.openedAt,
+		OpenDuration: c.openFor,
+		Cause:        cause,
+		clock:        c.cfg.clock,
+	}
+}
+
+// rejection returns the error for a call rejected while the circuit is
+// open or half-open.
+func (c *Circuit) rejection() error {
+	if c.openErr != nil {
+		return c.openErr
 	}
+	return ErrOpen
+}
+
+// rampSteps is how many multiples of the half-open limit WithRecoveryRamp
+// climbs through before lifting the concurrency cap.
+const rampSteps = 10
+
+// rampLimit returns the WithRecoveryRamp concurrency cap, ending the ramp
+// once its duration has elapsed.
+func (c *Circuit) rampLimit() int64 {
+	elapsed := c.cfg.clock.Now().Sub(c.recoveredAt)
+	if elapsed >= c.cfg.recoveryRamp {
+		c.ramping = false
+		return math.MaxInt64
+	}
+	step := int64(elapsed * rampSteps / c.cfg.recoveryRamp)
+	return int64(c.cfg.halfOpenRequests) * (1 + step)
+}
 
-	if c.cfg.onStateChange != nil {
-		c.cfg.onStateChange(c.name, from, to)
+// armAutoReset starts the WithAutoReset timer when the circuit leaves
+// Closed and cancels it when the circuit closes. The timer is not
+// restarted by Open and HalfOpen transitions in between, so it measures
+// how long the circuit has gone without closing.
+func (c *Circuit) armAutoReset() {
+	if c.cfg.autoReset <= 0 {
+		return
+	}
+	if c.state == Closed {
+		c.disarmAutoReset()
+		return
 	}
+	if c.autoResetStop != nil {
+		return
+	}
+	c.autoResetGen++
+	gen := c.autoResetGen
+	c.autoResetStop = afterFunc(c.cfg.clock, c.cfg.autoReset, func() {
+		c.autoResetFired(gen)
+	})
 }
 
-func defaultCondition(err error) bool {
-	return err != nil
+func (c *Circuit) disarmAutoReset() {
+	if c.autoResetStop != nil {
+		c.autoResetStop()
+		c.autoResetStop = nil
+	}
+}
+
+func (c *Circuit) autoResetFired(gen uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.autoResetStop == nil || gen != c.autoResetGen {
+		return
+	}
+	c.autoResetStop = nil
+	if c.disabled.Load() || c.shutdown.Load() || c.state == Closed {
+		return
+	}
+	c.reset()
+	c.emit(CircuitEvent{
+		Kind: EventAutoReset,
+		Name: c.fullName,
+		At:   c.cfg.clock.Now(),
+	})
+}
+
+// notify wakes goroutines blocked in WaitForState. Must be called with mu held.
+func (c *Circuit) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// defaultCondition counts any non-nil error as a failure, except
+// cancellations caused by the caller's own context unless
+// WithCountCanceled is set.
+func (c *Circuit) defaultCondition(ctx context.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+	if !c.cfg.countCanceled && errors.Is(err, context.Canceled) && errors.Is(ctx.Err(), context.Canceled) {
+		return false
+	}
+	return true
 }
@@ -3,10 +3,15 @@ package breaker_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/bjaus/breaker"
+	breakertesting "github.com/bjaus/breaker/testing"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
@@ -14,8 +19,16 @@ import (
 var errTest = errors.New("test error")
 
 // fakeClock is a test clock that allows manual time control.
+// AfterFunc callbacks run synchronously from Advance once due.
 type fakeClock struct {
-	now time.Time
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	at      time.Time
+	f       func()
+	stopped bool
 }
 
 func newFakeClock() *fakeClock {
@@ -26,8 +39,32 @@ func (c *fakeClock) Now() time.Time {
 	return c.now
 }
 
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) func() {
+	t := &fakeTimer{at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return func() { t.stopped = true }
+}
+
 func (c *fakeClock) Advance(d time.Duration) {
 	c.now = c.now.Add(d)
+
+	var due []*fakeTimer
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		switch {
+		case t.stopped:
+		case !t.at.After(c.now):
+			due = append(due, t)
+		default:
+			pending = append(pending, t)
+		}
+	}
+	c.timers = pending
+	for _, t := range due {
+		if !t.stopped {
+			t.f()
+		}
+	}
 }
 
 type BreakerSuite struct {
@@ -102,6 +139,27 @@ func (s *BreakerSuite) TestDo_CountsConsecutiveFailures() {
 	s.Equal(breaker.Open, c.State(), "expected Open after 3 failures")
 }
 
+func (s *BreakerSuite) TestDo_ZeroFailureThresholdUsesDefault() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(0),
+		breaker.WithClock(s.clock),
+	)
+
+	for range breaker.DefaultFailureThreshold - 1 {
+		s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		}), errTest)
+	}
+
+	s.Equal(breaker.Closed, c.State(), "expected zero threshold not to open on early failures")
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+
+	s.Equal(breaker.Open, c.State(), "expected default threshold to apply")
+}
+
 func (s *BreakerSuite) TestDo_ResetsFailureCountOnSuccess() {
 	c := breaker.New("test",
 		breaker.WithFailureThreshold(3),
@@ -115,17 +173,58 @@ func (s *BreakerSuite) TestDo_ResetsFailureCountOnSuccess() {
 		return errTest
 	}), errTest)
 
-	failures, _ := c.Counts()
+	failures := c.Counts().Failures
 	s.Equal(2, failures)
 
 	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
 		return nil
 	}))
 
-	failures, _ = c.Counts()
+	failures = c.Counts().Failures
 	s.Equal(0, failures, "expected 0 failures after success")
 }
 
+func (s *BreakerSuite) TestWindow_ResetsStaleFailures() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(3),
+		breaker.WithWindow(time.Minute),
+		breaker.WithClock(s.clock),
+	)
+
+	for range 2 {
+		s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		}), errTest)
+	}
+
+	s.clock.Advance(2 * time.Hour)
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+
+	s.Equal(breaker.Closed, c.State())
+	failures := c.Counts().Failures
+	s.Equal(1, failures, "expected stale failures to be discarded")
+}
+
+func (s *BreakerSuite) TestWindow_KeepsRecentFailures() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(3),
+		breaker.WithWindow(time.Minute),
+		breaker.WithClock(s.clock),
+	)
+
+	for range 3 {
+		s.clock.Advance(30 * time.Second)
+		s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		}), errTest)
+	}
+
+	s.Equal(breaker.Open, c.State())
+}
+
 func (s *BreakerSuite) TestDo_RejectsCallsWhenOpen() {
 	c := breaker.New("test",
 		breaker.WithFailureThreshold(1),
@@ -380,6 +479,94 @@ func (s *BreakerSuite) TestCondition_NotInvertsCondition() {
 	s.True(inverted(errTest), "expected Not(alwaysFalse) to return true")
 }
 
+func (s *BreakerSuite) TestCondition_ContextConditionExcludesCallerCancellation() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+		breaker.WithContextCondition(func(ctx context.Context, err error) bool {
+			return err != nil && ctx.Err() == nil
+		}),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s.ErrorIs(c.Do(ctx, func(ctx context.Context) error {
+		return ctx.Err()
+	}), context.Canceled)
+
+	s.Equal(breaker.Closed, c.State(), "expected caller cancellation not to count")
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestCondition_ContextConditionTakesPrecedenceOverIf() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+		breaker.If(func(err error) bool { return true }),
+		breaker.WithContextCondition(func(ctx context.Context, err error) bool {
+			return false
+		}),
+	)
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *BreakerSuite) TestCondition_DefaultIgnoresCallerCancellation() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s.ErrorIs(c.Do(ctx, func(ctx context.Context) error {
+		return ctx.Err()
+	}), context.Canceled)
+
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *BreakerSuite) TestCondition_DefaultCountsCancellationNotFromCaller() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return context.Canceled
+	}), context.Canceled)
+
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestCondition_WithCountCanceledRestoresOldBehavior() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithCountCanceled(true),
+		breaker.WithClock(s.clock),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s.ErrorIs(c.Do(ctx, func(ctx context.Context) error {
+		return ctx.Err()
+	}), context.Canceled)
+
+	s.Equal(breaker.Open, c.State())
+}
+
 func (s *BreakerSuite) TestHooks_OnStateChangeCalledOnTransition() {
 	var transitions []struct {
 		name     string
@@ -476,13 +663,13 @@ func (s *BreakerSuite) TestReset_ResetsCircuitToClosed() {
 
 	s.Equal(breaker.Open, c.State())
 
-	c.Reset()
+	s.True(c.ResetAndReport())
 
 	s.Equal(breaker.Closed, c.State())
 
-	failures, successes := c.Counts()
-	s.Zero(failures)
-	s.Zero(successes)
+	counts := c.Counts()
+	s.Zero(counts.Failures)
+	s.Zero(counts.Successes)
 }
 
 func (s *BreakerSuite) TestReset_TriggersOnStateChange() {
@@ -500,7 +687,7 @@ func (s *BreakerSuite) TestReset_TriggersOnStateChange() {
 		return errTest
 	}), errTest)
 
-	c.Reset()
+	c.Reset() //nolint:staticcheck // covers the deprecated Reset
 
 	s.Require().Len(transitions, 2)
 	s.Equal(breaker.Closed, transitions[1])
@@ -517,83 +704,3396 @@ func (s *BreakerSuite) TestReset_WhenAlreadyClosedIsNoOp() {
 
 	s.Equal(breaker.Closed, c.State())
 
-	c.Reset()
+	s.False(c.ResetAndReport())
 
 	s.Zero(stateChanges)
 }
 
-func (s *BreakerSuite) TestCounts_TracksFailures() {
+func (s *BreakerSuite) TestClearCounts_ZeroesCountsInClosed() {
 	c := breaker.New("test",
 		breaker.WithFailureThreshold(10),
 		breaker.WithClock(s.clock),
 	)
 
 	for range 3 {
-		s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
 			return errTest
-		}), errTest)
+		})
 	}
 
-	failures, successes := c.Counts()
-	s.Equal(3, failures)
-	s.Zero(successes)
+	c.ClearCounts()
+
+	counts := c.Counts()
+	s.Zero(counts.Failures)
+	s.Zero(counts.Successes)
+	s.Equal(breaker.Closed, c.State())
 }
 
-func (s *BreakerSuite) TestCounts_TracksSuccessesInHalfOpen() {
+func (s *BreakerSuite) TestClearCounts_LeavesOpenCircuitOpen() {
+	stateChanges := 0
 	c := breaker.New("test",
 		breaker.WithFailureThreshold(1),
-		breaker.WithSuccessThreshold(5),
-		breaker.WithHalfOpenRequests(5),
-		breaker.WithOpenDuration(10*time.Second),
 		breaker.WithClock(s.clock),
+		breaker.OnStateChange(func(name string, from, to breaker.State) {
+			stateChanges++
+		}),
 	)
 
 	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
 		return errTest
 	}), errTest)
-	s.clock.Advance(11 * time.Second)
+	openedAt := c.Snapshot().OpenedAt
 
-	for range 3 {
-		s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
-			return nil
-		}))
-	}
+	c.ClearCounts()
 
-	_, successes := c.Counts()
-	s.Equal(3, successes)
+	s.Equal(breaker.Open, c.State())
+	s.Equal(openedAt, c.Snapshot().OpenedAt)
+	s.Equal(1, stateChanges)
 }
 
-func TestIsOpen(t *testing.T) {
-	tests := map[string]struct {
-		err  error
-		want bool
-	}{
-		"returns true for ErrOpen":      {err: breaker.ErrOpen, want: true},
-		"returns false for other error": {err: errTest, want: false},
-		"returns false for nil":         {err: nil, want: false},
+func (s *BreakerSuite) TestInitialState_OpenHonorsOpenedAt() {
+	c := breaker.New("test",
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithInitialState(breaker.Open, s.clock.Now().Add(-45*time.Second)),
+		breaker.WithClock(s.clock),
+	)
+
+	s.Equal(breaker.Open, c.State())
+	s.Equal(s.clock.Now().Add(-45*time.Second), c.Snapshot().OpenedAt)
+
+	s.clock.Advance(15 * time.Second)
+
+	s.Equal(breaker.HalfOpen, c.State())
+}
+
+func (s *BreakerSuite) TestInitialState_OpenWithZeroOpenedAtStartsNow() {
+	c := breaker.New("test",
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithInitialState(breaker.Open, time.Time{}),
+		breaker.WithClock(s.clock),
+	)
+
+	s.Equal(s.clock.Now(), c.Snapshot().OpenedAt)
+
+	err := c.Do(context.Background(), func(ctx context.Context) error {
+		s.Fail("function should not be called when circuit is open")
+		return nil
+	})
+	s.True(breaker.IsOpen(err))
+}
+
+func (s *BreakerSuite) TestInitialState_ZeroOpenedAtStartsNowWithEveryConstructor() {
+	opts := []breaker.Option{
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithInitialState(breaker.Open, time.Time{}),
+		breaker.WithClock(s.clock),
+	}
+	constructors := map[string]func() *breaker.Circuit{
+		"New": func() *breaker.Circuit { return breaker.New("test", opts...) },
+		"NewWithError": func() *breaker.Circuit {
+			c, err := breaker.NewWithError("test", opts...)
+			s.Require().NoError(err)
+			return c
+		},
+		"MustNew": func() *breaker.Circuit { return breaker.MustNew("test", opts...) },
 	}
 
-	for name, tc := range tests {
-		t.Run(name, func(t *testing.T) {
-			require.Equal(t, tc.want, breaker.IsOpen(tc.err))
+	for name, newCircuit := range constructors {
+		s.Run(name, func() {
+			c := newCircuit()
+
+			s.Equal(breaker.Open, c.State())
+			s.Equal(s.clock.Now(), c.Snapshot().OpenedAt)
 		})
 	}
 }
 
-func TestState_String(t *testing.T) {
-	tests := map[string]struct {
-		state breaker.State
-		want  string
-	}{
-		"closed":    {state: breaker.Closed, want: "closed"},
-		"open":      {state: breaker.Open, want: "open"},
-		"half-open": {state: breaker.HalfOpen, want: "half-open"},
-		"unknown":   {state: breaker.State(99), want: "unknown"},
+func (s *BreakerSuite) TestInitialState_IgnoresOpenedAtUnlessOpen() {
+	c := breaker.New("test",
+		breaker.WithInitialState(breaker.HalfOpen, s.clock.Now().Add(-time.Hour)),
+		breaker.WithClock(s.clock),
+	)
+
+	s.Equal(breaker.HalfOpen, c.State())
+	s.True(c.Snapshot().OpenedAt.IsZero())
+}
+
+func (s *BreakerSuite) TestInitialState_HalfOpenDowntimeStartsAtCreation() {
+	var downtime time.Duration
+	c := breaker.New("test",
+		breaker.WithInitialState(breaker.HalfOpen, time.Time{}),
+		breaker.WithSuccessThreshold(1),
+		breaker.WithClock(s.clock),
+		breaker.OnRecover(func(name string, d time.Duration) {
+			downtime = d
+		}),
+	)
+
+	s.clock.Advance(time.Minute)
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+
+	s.Equal(breaker.Closed, c.State())
+	s.Equal(time.Minute, downtime)
+}
+
+func (s *BreakerSuite) TestInitialState_DoesNotFireOnStateChange() {
+	stateChanges := 0
+	c := breaker.New("test",
+		breaker.WithInitialState(breaker.Open, time.Time{}),
+		breaker.WithClock(s.clock),
+		breaker.OnStateChange(func(name string, from, to breaker.State) {
+			stateChanges++
+		}),
+	)
+
+	s.Equal(breaker.Open, c.State())
+	s.Zero(stateChanges)
+}
+
+func (s *BreakerSuite) TestAutoReset_ClosesStuckCircuit() {
+	var resets []string
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Hour),
+		breaker.WithAutoReset(10*time.Minute),
+		breaker.WithClock(s.clock),
+		breaker.OnAutoReset(func(name string) {
+			resets = append(resets, name)
+		}),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(9 * time.Minute)
+	s.Equal(breaker.Open, c.State())
+	s.Empty(resets)
+
+	s.clock.Advance(time.Minute)
+
+	s.Equal(breaker.Closed, c.State())
+	s.Equal([]string{"test"}, resets)
+}
+
+func (s *BreakerSuite) TestAutoReset_CountsHalfOpenAsNotClosed() {
+	resets := 0
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithAutoReset(10*time.Minute),
+		breaker.WithClock(s.clock),
+		breaker.OnAutoReset(func(name string) {
+			resets++
+		}),
+	)
+
+	fail := func(ctx context.Context) error { return errTest }
+	_ = c.Do(context.Background(), fail)
+	for range 9 {
+		s.clock.Advance(time.Minute)
+		_ = c.Do(context.Background(), fail)
 	}
+	s.Equal(breaker.Open, c.State())
 
-	for name, tc := range tests {
-		t.Run(name, func(t *testing.T) {
-			require.Equal(t, tc.want, tc.state.String())
+	s.clock.Advance(time.Minute)
+
+	s.Equal(1, resets)
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *BreakerSuite) TestAutoReset_CancelledWhenCircuitCloses() {
+	resets := 0
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithSuccessThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithAutoReset(10*time.Minute),
+		breaker.WithClock(s.clock),
+		breaker.OnAutoReset(func(name string) {
+			resets++
+		}),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(time.Minute)
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+	s.Require().Equal(breaker.Closed, c.State())
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(9 * time.Minute)
+
+	s.Zero(resets, "timer should restart when the circuit opens again")
+}
+
+func (s *BreakerSuite) TestAutoReset_DisabledByDefault() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Hour),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(30 * time.Minute)
+
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestRecoverPanics_RecordsPanicAsFailure() {
+	var hookErr error
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithRecoverPanics(false),
+		breaker.WithClock(s.clock),
+		breaker.OnCall(func(name string, state breaker.State, err error) {
+			hookErr = err
+		}),
+	)
+
+	err := c.Do(context.Background(), func(ctx context.Context) error {
+		panic("boom")
+	})
+
+	var pe *breaker.PanicError
+	s.Require().ErrorAs(err, &pe)
+	s.Equal("boom", pe.Value)
+	s.NotEmpty(pe.Stack)
+	s.Equal(err, hookErr)
+	s.Equal(breaker.Open, c.State())
+	s.Zero(c.InFlight())
+}
+
+func (s *BreakerSuite) TestRecoverPanics_Repanics() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithRecoverPanics(true),
+		breaker.WithClock(s.clock),
+	)
+
+	s.PanicsWithValue("boom", func() {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			panic("boom")
+		})
+	})
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestRecoverPanics_DisabledByDefault() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+
+	s.Panics(func() {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			panic("boom")
+		})
+	})
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *BreakerSuite) TestPanic_ReleasesHalfOpenSlot() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithSuccessThreshold(1),
+		breaker.WithHalfOpenRequests(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(time.Minute)
+
+	s.Panics(func() {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			panic("boom")
+		})
+	})
+	s.Zero(c.InFlight())
+	s.Equal(breaker.HalfOpen, c.State())
+
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *BreakerSuite) TestUnrecorded_KeepsFailureStreak() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(2),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	err := c.Do(context.Background(), func(ctx context.Context) error {
+		return breaker.Unrecorded(context.Canceled)
+	})
+	s.Equal(context.Canceled, err)
+	s.Equal(1, c.Counts().Failures)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestUnrecorded_ReleasesHalfOpenSlot() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithSuccessThreshold(1),
+		breaker.WithHalfOpenRequests(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(time.Minute)
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return breaker.Unrecorded(errTest)
+	}), errTest)
+	s.Equal(breaker.HalfOpen, c.State())
+
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *BreakerSuite) TestOpenError_WrapsTriggeringError() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(2),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return io.ErrUnexpectedEOF
+	})
+
+	err := c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	})
+
+	s.ErrorIs(err, breaker.ErrOpen)
+	s.ErrorIs(err, io.ErrUnexpectedEOF)
+	s.NotErrorIs(err, errTest)
+
+	var openErr *breaker.OpenError
+	s.Require().ErrorAs(err, &openErr)
+	s.Equal("test", openErr.Name)
+	s.Equal(c.Snapshot().OpenedAt, openErr.OpenedAt)
+}
+
+func (s *BreakerSuite) TestOpenError_WrapsFailedHalfOpenCall() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(time.Minute)
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return io.ErrUnexpectedEOF
+	})
+
+	err := c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	})
+
+	s.ErrorIs(err, io.ErrUnexpectedEOF)
+}
+
+func (s *BreakerSuite) TestCooldown_ExtendsOpenAfterFlap() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithSuccessThreshold(1),
+		breaker.WithOpenDuration(10*time.Second),
+		breaker.WithCooldown(5*time.Second),
+		breaker.WithClock(s.clock),
+	)
+	fail := func(ctx context.Context) error { return errTest }
+
+	_ = c.Do(context.Background(), fail)
+	s.clock.Advance(10 * time.Second)
+	s.Require().Equal(breaker.HalfOpen, c.State())
+
+	_ = c.Do(context.Background(), fail)
+	s.clock.Advance(14 * time.Second)
+	s.Equal(breaker.Open, c.State(), "first re-trip adds one cooldown")
+	s.clock.Advance(time.Second)
+	s.Require().Equal(breaker.HalfOpen, c.State())
+
+	_ = c.Do(context.Background(), fail)
+	s.clock.Advance(19 * time.Second)
+	s.Equal(breaker.Open, c.State(), "second quick re-trip adds another cooldown")
+	s.clock.Advance(time.Second)
+	s.Require().Equal(breaker.HalfOpen, c.State())
+
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error { return nil }))
+	s.Require().Equal(breaker.Closed, c.State())
+
+	_ = c.Do(context.Background(), fail)
+	s.clock.Advance(10 * time.Second)
+	s.Equal(breaker.HalfOpen, c.State(), "closing resets the cooldown")
+}
+
+func (s *BreakerSuite) TestCooldown_SlowRetripDoesNotAccumulate() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(10*time.Second),
+		breaker.WithCooldown(5*time.Second),
+		breaker.WithClock(s.clock),
+	)
+	fail := func(ctx context.Context) error { return errTest }
+
+	_ = c.Do(context.Background(), fail)
+	s.clock.Advance(10 * time.Second)
+	_ = c.Do(context.Background(), fail)
+	s.clock.Advance(15 * time.Second)
+	s.Require().Equal(breaker.HalfOpen, c.State())
+
+	s.clock.Advance(6 * time.Second)
+	_ = c.Do(context.Background(), fail)
+	s.clock.Advance(15 * time.Second)
+
+	s.Equal(breaker.HalfOpen, c.State())
+}
+
+func (s *BreakerSuite) TestOpenError_RetryAfter() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(30*time.Second),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(10 * time.Second)
+
+	var openErr *breaker.OpenError
+	s.Require().ErrorAs(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}), &openErr)
+
+	s.Equal(20*time.Second, openErr.RetryAfter())
+
+	s.clock.Advance(time.Minute)
+	s.Zero(openErr.RetryAfter())
+}
+
+func (s *BreakerSuite) TestOpenError_RetryAfterIncludesCooldown() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(10*time.Second),
+		breaker.WithCooldown(5*time.Second),
+		breaker.WithClock(s.clock),
+	)
+	fail := func(ctx context.Context) error { return errTest }
+
+	_ = c.Do(context.Background(), fail)
+	s.clock.Advance(10 * time.Second)
+	_ = c.Do(context.Background(), fail)
+
+	var openErr *breaker.OpenError
+	s.Require().ErrorAs(c.Do(context.Background(), fail), &openErr)
+
+	s.Equal(15*time.Second, openErr.RetryAfter())
+}
+
+func (s *BreakerSuite) TestOpenError_RetryAfterWhenStartedOpen() {
+	c := breaker.New("test",
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithInitialState(breaker.Open, s.clock.Now().Add(-15*time.Second)),
+		breaker.WithClock(s.clock),
+	)
+
+	var openErr *breaker.OpenError
+	s.Require().ErrorAs(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}), &openErr)
+
+	s.Nil(openErr.Cause)
+	s.Equal(45*time.Second, openErr.RetryAfter())
+}
+
+func (s *BreakerSuite) TestCallLimit_OpensAfterLimit() {
+	c := breaker.New("test",
+		breaker.WithCallLimit(3),
+		breaker.WithSuccessThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithClock(s.clock),
+	)
+	succeed := func(ctx context.Context) error { return nil }
+
+	s.Equal(3, c.Snapshot().CallsRemaining)
+	s.NoError(c.Do(context.Background(), succeed))
+	s.NoError(c.Do(context.Background(), succeed))
+	s.Equal(1, c.Snapshot().CallsRemaining)
+	s.NoError(c.Do(context.Background(), succeed))
+	s.Equal(breaker.Open, c.State())
+	s.Zero(c.Snapshot().CallsRemaining)
+
+	err := c.Do(context.Background(), succeed)
+	s.ErrorIs(err, breaker.ErrCallLimitExceeded)
+	s.True(breaker.IsOpen(err))
+
+	s.clock.Advance(time.Minute)
+	s.NoError(c.Do(context.Background(), succeed))
+	s.Require().Equal(breaker.Closed, c.State())
+	s.Equal(3, c.Snapshot().CallsRemaining)
+}
+
+func (s *BreakerSuite) TestCallLimit_CountsFailures() {
+	c := breaker.New("test",
+		breaker.WithCallLimit(2),
+		breaker.WithFailureThreshold(10),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error { return errTest })
+	_ = c.Do(context.Background(), func(ctx context.Context) error { return nil })
+
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestCallLimit_UnlimitedByDefault() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+
+	s.Equal(-1, c.Snapshot().CallsRemaining)
+}
+
+func (s *BreakerSuite) TestRWMutex_ReadsReportDueHalfOpenWithoutTransition() {
+	var transitions []breaker.State
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithRWMutex(),
+		breaker.WithClock(s.clock),
+		breaker.OnStateChange(func(name string, from, to breaker.State) {
+			transitions = append(transitions, to)
+		}),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(time.Minute)
+
+	s.Equal(breaker.HalfOpen, c.State())
+	s.Equal(breaker.HalfOpen, c.Snapshot().State)
+	s.Equal([]breaker.State{breaker.Open}, transitions)
+
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+	s.Equal([]breaker.State{breaker.Open, breaker.HalfOpen}, transitions)
+}
+
+func (s *BreakerSuite) TestRWMutex_ConcurrentReadsAndCalls() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(3),
+		breaker.WithOpenDuration(time.Millisecond),
+		breaker.WithRWMutex(),
+	)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			for j := range 200 {
+				if i%2 == 0 {
+					_ = c.Do(context.Background(), func(ctx context.Context) error {
+						if j%3 == 0 {
+							return errTest
+						}
+						return nil
+					})
+					continue
+				}
+				_ = c.State()
+				_ = c.Counts()
+				_ = c.Snapshot()
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func (s *BreakerSuite) TestDoResult_ReportsReopenedCircuit() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithClock(s.clock),
+	)
+
+	res := c.DoResult(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.ErrorIs(res.Err, errTest)
+	s.False(res.ReopenedCircuit, "expected tripping from Closed not to count as reopening")
+
+	res = c.DoResult(context.Background(), func(ctx context.Context) error {
+		return nil
+	})
+	s.True(breaker.IsOpen(res.Err))
+	s.False(res.ReopenedCircuit, "expected a rejected call not to count as reopening")
+
+	s.clock.Advance(time.Minute)
+	res = c.DoResult(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.ErrorIs(res.Err, errTest)
+	s.True(res.ReopenedCircuit)
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestDoResult_SuccessDoesNotReopen() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(time.Minute)
+
+	res := c.DoResult(context.Background(), func(ctx context.Context) error {
+		return nil
+	})
+	s.NoError(res.Err)
+	s.False(res.ReopenedCircuit)
+}
+
+func (s *BreakerSuite) TestDoResult_FailedProbeReopens() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithProbe(func(ctx context.Context) error {
+			return errTest
+		}),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(time.Minute)
+
+	res := c.DoResult(context.Background(), func(ctx context.Context) error {
+		return nil
+	})
+	s.True(breaker.IsOpen(res.Err))
+	s.True(res.ReopenedCircuit)
+}
+
+func (s *BreakerSuite) TestFairHalfOpen_LimitsConcurrentAdmissions() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithHalfOpenRequests(3),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithFairHalfOpen(),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(time.Minute)
+
+	release := make(chan struct{})
+	var admitted, rejected atomic.Int32
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Go(func() {
+			err := c.Do(context.Background(), func(ctx context.Context) error {
+				admitted.Add(1)
+				<-release
+				return nil
+			})
+			if breaker.IsOpen(err) {
+				rejected.Add(1)
+			}
+		})
+	}
+	s.Eventually(func() bool {
+		return admitted.Load()+rejected.Load() == 20
+	}, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	s.Equal(int32(3), admitted.Load())
+	s.Equal(int32(17), rejected.Load())
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *BreakerSuite) TestErr_ReturnsLastCountedFailure() {
+	ignored := errors.New("ignored")
+	first := errors.New("first")
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(2),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.If(func(err error) bool {
+			return err != nil && !errors.Is(err, ignored)
+		}),
+		breaker.WithClock(s.clock),
+	)
+	s.NoError(c.Err())
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return first
+	})
+	s.ErrorIs(c.Err(), first)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return ignored
+	})
+	s.NoError(c.Err(), "expected an ignored error to reset the count like a success")
+
+	for range 2 {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		})
+	}
+	s.Equal(breaker.Open, c.State())
+	s.ErrorIs(c.Err(), errTest, "expected the error that opened the circuit")
+}
+
+func (s *BreakerSuite) TestErr_ClearedOnSuccessAndRecovery() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(2),
+		breaker.WithSuccessThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+	s.NoError(c.Err(), "expected a success to clear the failure count and error")
+
+	for range 2 {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		})
+	}
+	s.clock.Advance(time.Minute)
+	s.ErrorIs(c.Err(), errTest, "expected half-open to keep the error")
+
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+	s.Equal(breaker.Closed, c.State())
+	s.NoError(c.Err())
+}
+
+func (s *BreakerSuite) TestErr_ClearedOnReset() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.ErrorIs(c.Err(), errTest)
+	s.Equal(errTest.Error(), c.Snapshot().LastErr)
+
+	s.True(c.ResetAndReport())
+	s.NoError(c.Err())
+	s.Empty(c.Snapshot().LastErr)
+}
+
+func (s *BreakerSuite) TestOnRecover_ReportsTotalDowntime() {
+	var downtimes []time.Duration
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithSuccessThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithClock(s.clock),
+		breaker.OnRecover(func(name string, downtime time.Duration) {
+			downtimes = append(downtimes, downtime)
+		}),
+	)
+
+	fail := func(ctx context.Context) error { return errTest }
+	_ = c.Do(context.Background(), fail)
+	s.clock.Advance(time.Minute)
+	_ = c.Do(context.Background(), fail)
+	s.clock.Advance(90 * time.Second)
+	s.Empty(downtimes)
+
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+
+	s.Equal(breaker.Closed, c.State())
+	s.Equal([]time.Duration{150 * time.Second}, downtimes, "expected downtime to span the failed trial")
+}
+
+func (s *BreakerSuite) TestOnProbe_ReportsFailedTrialThatReopens() {
+	var probes []bool
+	var states []breaker.State
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithClock(s.clock),
+		breaker.OnProbe(func(name string, success bool) {
+			probes = append(probes, success)
+		}),
+		breaker.OnStateChange(func(name string, from, to breaker.State) {
+			states = append(states, to)
+		}),
+	)
+
+	fail := func(ctx context.Context) error { return errTest }
+	_ = c.Do(context.Background(), fail)
+	s.Empty(probes, "closed-state calls are not probes")
+
+	s.clock.Advance(time.Minute)
+	_ = c.Do(context.Background(), fail)
+
+	s.Equal([]bool{false}, probes)
+	s.Equal(breaker.Open, c.State())
+	s.Equal([]breaker.State{breaker.Open, breaker.HalfOpen, breaker.Open}, states)
+}
+
+func (s *BreakerSuite) TestOnProbe_ReportsEachSuccessfulTrial() {
+	var probes []bool
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithSuccessThreshold(2),
+		breaker.WithHalfOpenRequests(2),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithClock(s.clock),
+		breaker.OnProbe(func(name string, success bool) {
+			probes = append(probes, success)
+		}),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error { return errTest })
+	s.clock.Advance(time.Minute)
+	for range 2 {
+		s.NoError(c.Do(context.Background(), func(ctx context.Context) error { return nil }))
+	}
+
+	s.Equal([]bool{true, true}, probes)
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *BreakerSuite) TestOnProbe_ReportsTripImmediatelyOnAsFailure() {
+	fatal := errors.New("fatal")
+	var probes []bool
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithTripImmediatelyOn(breaker.IsErr(fatal)),
+		breaker.WithClock(s.clock),
+		breaker.OnProbe(func(name string, success bool) {
+			probes = append(probes, success)
+		}),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error { return errTest })
+	s.clock.Advance(time.Minute)
+	_ = c.Do(context.Background(), func(ctx context.Context) error { return fatal })
+
+	s.Equal([]bool{false}, probes)
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestOnRecover_NotCalledOnReset() {
+	recovered := 0
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithAutoReset(10*time.Minute),
+		breaker.WithClock(s.clock),
+		breaker.OnRecover(func(name string, downtime time.Duration) {
+			recovered++
+		}),
+	)
+
+	fail := func(ctx context.Context) error { return errTest }
+	_ = c.Do(context.Background(), fail)
+	s.clock.Advance(time.Minute)
+	s.Equal(breaker.HalfOpen, c.State())
+	c.ResetAndReport()
+	s.Equal(breaker.Closed, c.State())
+
+	_ = c.Do(context.Background(), fail)
+	s.clock.Advance(10 * time.Minute)
+	s.Equal(breaker.Closed, c.State(), "expected auto-reset to close the circuit")
+
+	s.Zero(recovered)
+}
+
+func (s *BreakerSuite) TestForceOpen_OpensFromAnyState() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithClock(s.clock),
+	)
+
+	c.ForceOpen()
+	s.Equal(breaker.Open, c.State())
+	s.True(breaker.IsOpen(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	})))
+
+	s.clock.Advance(time.Minute)
+	s.Equal(breaker.HalfOpen, c.State())
+	c.ForceOpen()
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestForceOpen_IgnoredWhenDisabled() {
+	c := breaker.New("test", breaker.WithDisabled(), breaker.WithClock(s.clock))
+
+	c.ForceOpen()
+
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *BreakerSuite) TestOpenFor_OverridesOpenDuration() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithClock(s.clock),
+	)
+
+	err := c.Do(context.Background(), func(ctx context.Context) error {
+		return breaker.OpenFor(errTest, 5*time.Minute)
+	})
+	s.ErrorIs(err, errTest)
+	s.Equal(errTest.Error(), err.Error())
+
+	s.clock.Advance(time.Minute)
+	s.Equal(breaker.Open, c.State(), "expected the static open duration to be overridden")
+
+	var openErr *breaker.OpenError
+	s.Require().ErrorAs(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}), &openErr)
+	s.Equal(4*time.Minute, openErr.RetryAfter())
+
+	s.clock.Advance(4 * time.Minute)
+	s.Equal(breaker.HalfOpen, c.State())
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.Equal(breaker.Open, c.State())
+	s.clock.Advance(time.Minute)
+	s.Equal(breaker.HalfOpen, c.State(), "expected later openings to use the static duration")
+}
+
+func (s *BreakerSuite) TestOpenFor_IgnoredWhenCircuitStaysClosed() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(2),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return breaker.OpenFor(errTest, time.Hour)
+	})
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.Equal(breaker.Open, c.State())
+
+	s.clock.Advance(time.Minute)
+	s.Equal(breaker.HalfOpen, c.State())
+}
+
+func (s *BreakerSuite) TestMaxHalfOpenDuration_ReopensLingeringCircuit() {
+	var transitions []breaker.State
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithSuccessThreshold(3),
+		breaker.WithHalfOpenRequests(3),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithMaxHalfOpenDuration(30*time.Second),
+		breaker.WithClock(s.clock),
+		breaker.OnStateChange(func(name string, from, to breaker.State) {
+			transitions = append(transitions, to)
+		}),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(time.Minute)
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+	s.clock.Advance(29 * time.Second)
+	s.Equal(breaker.HalfOpen, c.State())
+
+	s.clock.Advance(time.Second)
+
+	s.Equal(breaker.Open, c.State())
+	s.Equal([]breaker.State{breaker.Open, breaker.HalfOpen}, transitions, "expected State not to reopen the circuit")
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}), breaker.ErrOpen)
+	s.Equal([]breaker.State{breaker.Open, breaker.HalfOpen, breaker.Open}, transitions)
+	s.clock.Advance(time.Minute)
+	s.Equal(breaker.HalfOpen, c.State(), "expected a fresh open period before the next trial")
+}
+
+func (s *BreakerSuite) TestMaxHalfOpenDuration_MeasuredFromTransition() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithMaxHalfOpenDuration(30*time.Second),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(time.Hour)
+
+	s.Equal(breaker.HalfOpen, c.State(), "expected a late observer to still see half-open")
+	s.clock.Advance(30 * time.Second)
+	s.Equal(breaker.HalfOpen, c.State(), "expected no transition before the next call")
+
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+	s.clock.Advance(29 * time.Second)
+	s.Equal(breaker.HalfOpen, c.State())
+	s.clock.Advance(time.Second)
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestMaxHalfOpenDuration_RecoveryWithinLimitCloses() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithSuccessThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithMaxHalfOpenDuration(30*time.Second),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(time.Minute)
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+	s.clock.Advance(time.Hour)
+
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *BreakerSuite) TestProbeFunc_SuccessClosesAndRunsCall() {
+	var order []string
+	var recovered int
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithSuccessThreshold(3),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithProbeFunc(func(ctx context.Context) error {
+			order = append(order, "probe")
+			return nil
+		}),
+		breaker.WithClock(s.clock),
+		breaker.OnRecover(func(name string, downtime time.Duration) {
+			recovered++
+		}),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(time.Minute)
+
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		order = append(order, "call")
+		return nil
+	}))
+
+	s.Equal([]string{"probe", "call"}, order)
+	s.Equal(breaker.Closed, c.State(), "expected the probe to close without the success threshold")
+	s.Equal(1, recovered)
+}
+
+func (s *BreakerSuite) TestProbeFunc_FailureReopensWithoutCalling() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithProbeFunc(func(ctx context.Context) error {
+			return errTest
+		}),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(time.Minute)
+
+	res := c.DoResult(context.Background(), func(ctx context.Context) error {
+		s.Fail("function should not be called when the probe fails")
+		return nil
+	})
+
+	s.True(breaker.IsOpen(res.Err))
+	s.ErrorIs(res.Err, errTest)
+	s.True(res.ReopenedCircuit)
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestProbeFunc_OnlyReplacesFirstTrial() {
+	probes := 0
+	release := make(chan struct{})
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithSuccessThreshold(1),
+		breaker.WithHalfOpenRequests(2),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithProbeFunc(func(ctx context.Context) error {
+			probes++
+			<-release
+			return nil
+		}),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(time.Minute)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Do(context.Background(), func(ctx context.Context) error {
+			return nil
+		})
+	}()
+	s.Eventually(func() bool {
+		return c.InFlight() == 1
+	}, time.Second, time.Millisecond)
+
+	called := false
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		called = true
+		return nil
+	}))
+	s.True(called, "expected the second trial to run the caller's function")
+
+	close(release)
+	s.NoError(<-done)
+	s.Equal(1, probes)
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *BreakerSuite) TestFailureWeight_OpensOnWeightedSum() {
+	errUnavailable := errors.New("unavailable")
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(5),
+		breaker.WithFailureWeight(func(err error) int {
+			if errors.Is(err, errUnavailable) {
+				return 3
+			}
+			return 1
+		}),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errUnavailable
+	})
+	failures := c.Counts().Failures
+	s.Equal(4, failures)
+	s.Equal(breaker.Closed, c.State())
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errUnavailable
+	})
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestFailureWeight_ZeroIgnoresFailure() {
+	errMinor := errors.New("minor")
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(2),
+		breaker.WithFailureWeight(func(err error) int {
+			if errors.Is(err, errMinor) {
+				return 0
+			}
+			return 1
+		}),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	for range 5 {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			return errMinor
+		})
+	}
+	failures := c.Counts().Failures
+	s.Equal(1, failures, "expected ignored failures neither to count nor to reset the count")
+	s.ErrorIs(c.Err(), errTest)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestFailureWeight_ZeroInHalfOpenFreesTrialSlot() {
+	errMinor := errors.New("minor")
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithSuccessThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithFailureWeight(func(err error) int {
+			if errors.Is(err, errMinor) {
+				return 0
+			}
+			return 1
+		}),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(time.Minute)
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errMinor
+	}), errMinor)
+	s.Equal(breaker.HalfOpen, c.State(), "expected an ignored failure not to reopen the circuit")
+
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}), "expected the ignored trial's slot to be available")
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *BreakerSuite) TestFailureWeight_NotConsultedForSuccesses() {
+	consulted := 0
+	c := breaker.New("test",
+		breaker.WithFailureWeight(func(err error) int {
+			consulted++
+			return 1
+		}),
+		breaker.WithClock(s.clock),
+	)
+
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+
+	s.Zero(consulted)
+}
+
+func (s *BreakerSuite) TestVelocityThreshold_TripsOnSpikeDespiteSuccesses() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(100),
+		breaker.WithVelocityThreshold(3, time.Second),
+		breaker.WithClock(s.clock),
+	)
+
+	for range 2 {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		})
+		s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+			return nil
+		}))
+		s.clock.Advance(400 * time.Millisecond)
+	}
+	s.Equal(breaker.Closed, c.State())
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestVelocityThreshold_IgnoresSlowFailures() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(100),
+		breaker.WithVelocityThreshold(3, time.Second),
+		breaker.WithClock(s.clock),
+	)
+
+	for range 10 {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		})
+		s.clock.Advance(600 * time.Millisecond)
+	}
+
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *BreakerSuite) TestVelocityThreshold_ConsecutiveThresholdStillApplies() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(2),
+		breaker.WithVelocityThreshold(10, time.Second),
+		breaker.WithClock(s.clock),
+	)
+
+	for range 2 {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		})
+	}
+
+	s.Equal(breaker.Open, c.State())
+	snap := c.Snapshot()
+	s.Equal(2, snap.FailureThreshold)
+	s.Equal(10, snap.VelocityThreshold)
+	s.Equal(time.Second, snap.VelocityWindow)
+}
+
+func (s *BreakerSuite) TestVelocityThreshold_HistoryClearedOnReset() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(100),
+		breaker.WithVelocityThreshold(2, time.Second),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	c.ForceOpen()
+	c.ResetAndReport()
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *BreakerSuite) TestCounts_TracksLifetimeTotals() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(2),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithHalfOpenRequests(2),
+		breaker.WithClock(s.clock),
+	)
+
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+	for range 2 {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		})
+	}
+	for range 3 {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			return nil
+		})
+	}
+	s.clock.Advance(time.Minute)
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+
+	s.Equal(breaker.Stats{
+		Successes:       1,
+		HalfOpenProbes:  1,
+		TotalCalls:      4,
+		TotalRejections: 3,
+	}, c.Counts(), "expected totals to survive the state changes")
+}
+
+func (s *BreakerSuite) TestCounts2_MatchesCounts() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+
+	failures, successes := c.Counts2()
+
+	s.Equal(1, failures)
+	s.Zero(successes)
+}
+
+func (s *BreakerSuite) TestTripped_ReportsOpenWithoutTransitioning() {
+	var transitions []breaker.State
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithClock(s.clock),
+		breaker.OnStateChange(func(name string, from, to breaker.State) {
+			transitions = append(transitions, to)
+		}),
+	)
+	s.False(c.Tripped())
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.True(c.Tripped())
+
+	s.clock.Advance(time.Minute)
+	s.False(c.Tripped(), "expected an expired open period not to count as tripped")
+	s.Equal([]breaker.State{breaker.Open}, transitions, "expected Tripped not to move the circuit to half-open")
+}
+
+func (s *BreakerSuite) TestTripped_HonorsOpenForOverride() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return breaker.OpenFor(errTest, time.Hour)
+	})
+	s.clock.Advance(time.Minute)
+
+	s.True(c.Tripped())
+}
+
+func (s *BreakerSuite) TestTripped_FalseWhenNotRejecting() {
+	disabled := breaker.New("test", breaker.WithFailureThreshold(1), breaker.WithClock(s.clock))
+	disabled.ForceOpen()
+	disabled.Disable()
+	s.False(disabled.Tripped())
+
+	dryRun := breaker.New("test", breaker.WithFailureThreshold(1), breaker.WithDryRun(true), breaker.WithClock(s.clock))
+	_ = dryRun.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.Equal(breaker.Open, dryRun.State())
+	s.False(dryRun.Tripped())
+}
+
+func (s *BreakerSuite) TestState_DoesNotTransition() {
+	var transitions []breaker.State
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithClock(s.clock),
+		breaker.OnStateChange(func(name string, from, to breaker.State) {
+			transitions = append(transitions, to)
+		}),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(time.Minute)
+
+	for range 3 {
+		s.Equal(breaker.HalfOpen, c.State())
+		s.True(c.IsHalfOpen())
+		s.Equal(breaker.HalfOpen, c.Snapshot().State)
+	}
+	s.Equal([]breaker.State{breaker.Open}, transitions, "expected reading state not to fire OnStateChange")
+
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+	s.Equal([]breaker.State{breaker.Open, breaker.HalfOpen}, transitions)
+}
+
+func (s *BreakerSuite) TestFailureThresholdFunc_AppliesCurrentValue() {
+	threshold := 3
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithFailureThresholdFunc(func() int { return threshold }),
+		breaker.WithClock(s.clock),
+	)
+	fail := func() {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		})
+	}
+
+	fail()
+	fail()
+	s.Equal(breaker.Closed, c.State(), "expected the func to take precedence over the static threshold")
+	s.Equal(3, c.Snapshot().FailureThreshold)
+
+	threshold = 2
+	fail()
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestFailureThresholdFunc_FallsBackBelowOne() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(2),
+		breaker.WithFailureThresholdFunc(func() int { return 0 }),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.Equal(breaker.Closed, c.State())
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestReentrancyCheck_RejectsNestedCall() {
+	c := breaker.New("test", breaker.WithReentrancyCheck(), breaker.WithClock(s.clock))
+
+	innerCalled := false
+	var innerErr error
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		innerErr = c.Do(ctx, func(ctx context.Context) error {
+			innerCalled = true
+			return nil
+		})
+		return nil
+	}))
+
+	s.ErrorIs(innerErr, breaker.ErrReentrant)
+	s.False(innerCalled)
+	s.Equal(int64(1), c.Counts().TotalCalls)
+}
+
+func (s *BreakerSuite) TestReentrancyCheck_AllowsOtherCircuitsAndSequentialCalls() {
+	outer := breaker.New("outer", breaker.WithReentrancyCheck(), breaker.WithClock(s.clock))
+	inner := breaker.New("inner", breaker.WithReentrancyCheck(), breaker.WithClock(s.clock))
+
+	s.NoError(outer.Do(context.Background(), func(ctx context.Context) error {
+		return inner.Do(ctx, func(ctx context.Context) error {
+			return nil
+		})
+	}))
+	s.NoError(outer.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+}
+
+func (s *BreakerSuite) TestReentrancyCheck_OffByDefault() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+
+	innerCalled := false
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return c.Do(ctx, func(ctx context.Context) error {
+			innerCalled = true
+			return nil
+		})
+	}))
+	s.True(innerCalled)
+}
+
+func (s *BreakerSuite) TestMinClosedDuration_SuppressesFlapping() {
+	var transitions []breaker.State
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(2),
+		breaker.WithSuccessThreshold(1),
+		breaker.WithOpenDuration(time.Second),
+		breaker.WithMinClosedDuration(time.Minute),
+		breaker.WithClock(s.clock),
+		breaker.OnStateChange(func(name string, from, to breaker.State) {
+			transitions = append(transitions, to)
+		}),
+	)
+	fail := func() {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		})
+	}
+
+	fail()
+	fail()
+	s.clock.Advance(time.Second)
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+	s.Require().Equal(breaker.Closed, c.State())
+
+	for range 5 {
+		fail()
+	}
+	s.Equal(breaker.Closed, c.State(), "expected the circuit to stay closed within the minimum")
+	s.Equal(5, c.Counts().Failures)
+
+	s.clock.Advance(time.Minute)
+	fail()
+	s.Equal(breaker.Open, c.State())
+	s.Equal([]breaker.State{breaker.Open, breaker.HalfOpen, breaker.Closed, breaker.Open}, transitions)
+}
+
+func (s *BreakerSuite) TestMinClosedDuration_DoesNotApplyBeforeFirstClose() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithMinClosedDuration(time.Minute),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestMinClosedDuration_VelocitySpikeStillOpens() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(2),
+		breaker.WithVelocityThreshold(4, time.Second),
+		breaker.WithMinClosedDuration(time.Minute),
+		breaker.WithClock(s.clock),
+	)
+	c.ForceOpen()
+	c.ResetAndReport()
+
+	for range 3 {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		})
+	}
+	s.Equal(breaker.Closed, c.State())
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestTripImmediatelyOn_OpensOnFirstMatch() {
+	errRevoked := errors.New("credentials revoked")
+	var transitions []breaker.State
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(5),
+		breaker.WithSuccessThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithTripImmediatelyOn(func(err error) bool {
+			return errors.Is(err, errRevoked)
+		}),
+		breaker.WithClock(s.clock),
+		breaker.OnStateChange(func(name string, from, to breaker.State) {
+			transitions = append(transitions, to)
+		}),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.Equal(breaker.Closed, c.State())
+
+	err := c.Do(context.Background(), func(ctx context.Context) error {
+		return errRevoked
+	})
+	s.ErrorIs(err, errRevoked)
+	s.Equal(breaker.Open, c.State())
+	s.ErrorIs(c.Err(), errRevoked)
+
+	s.clock.Advance(time.Minute)
+	s.Equal(breaker.HalfOpen, c.State())
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+	s.Equal([]breaker.State{breaker.Open, breaker.HalfOpen, breaker.Closed}, transitions)
+}
+
+func (s *BreakerSuite) TestTripImmediatelyOn_OverridesIgnoringConditionAndWarmup() {
+	errRevoked := errors.New("credentials revoked")
+	c := breaker.New("test",
+		breaker.If(func(err error) bool { return false }),
+		breaker.WithWarmup(time.Hour),
+		breaker.WithTripImmediatelyOn(func(err error) bool {
+			return errors.Is(err, errRevoked)
+		}),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errRevoked
+	})
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestTripImmediatelyOn_ReopensHalfOpenCircuit() {
+	errRevoked := errors.New("credentials revoked")
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithTripImmediatelyOn(func(err error) bool {
+			return errors.Is(err, errRevoked)
+		}),
+		breaker.WithClock(s.clock),
+	)
+	c.ForceOpen()
+	s.clock.Advance(breaker.DefaultOpenDuration)
+
+	res := c.DoResult(context.Background(), func(ctx context.Context) error {
+		return errRevoked
+	})
+	s.ErrorIs(res.Err, errRevoked)
+	s.True(res.ReopenedCircuit)
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestDoTimed_ReturnsFnDuration() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+
+	d, err := c.DoTimed(context.Background(), func(ctx context.Context) error {
+		s.clock.Advance(150 * time.Millisecond)
+		return errTest
+	})
+
+	s.ErrorIs(err, errTest)
+	s.Equal(150*time.Millisecond, d)
+}
+
+func (s *BreakerSuite) TestDoTimed_ZeroWhenRejected() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+	c.ForceOpen()
+
+	d, err := c.DoTimed(context.Background(), func(ctx context.Context) error {
+		s.clock.Advance(time.Second)
+		return nil
+	})
+
+	s.True(breaker.IsOpen(err))
+	s.Zero(d)
+}
+
+func (s *BreakerSuite) TestRichErrors_MarksTrippingCall() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(2),
+		breaker.WithRichErrors(),
+		breaker.WithClock(s.clock),
+	)
+	fail := func() error {
+		return c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		})
+	}
+
+	first := fail()
+	s.ErrorIs(first, errTest)
+	s.Equal(errTest.Error(), first.Error())
+	s.False(breaker.IsTrip(first))
+	var ce *breaker.CircuitError
+	s.Require().ErrorAs(first, &ce)
+	s.Equal("test", ce.Name)
+	s.Equal(breaker.Closed, ce.State)
+
+	second := fail()
+	s.True(breaker.IsTrip(second))
+
+	rejected := fail()
+	s.True(breaker.IsOpen(rejected))
+	s.False(errors.As(rejected, &ce), "expected rejections not to be wrapped")
+}
+
+func (s *BreakerSuite) TestRichErrors_MarksFailedTrial() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithRichErrors(),
+		breaker.WithClock(s.clock),
+	)
+	c.ForceOpen()
+	s.clock.Advance(breaker.DefaultOpenDuration)
+
+	err := c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+
+	s.True(breaker.IsTrip(err))
+	var ce *breaker.CircuitError
+	s.Require().ErrorAs(err, &ce)
+	s.Equal(breaker.HalfOpen, ce.State)
+}
+
+func (s *BreakerSuite) TestRichErrors_OffByDefault() {
+	c := breaker.New("test", breaker.WithFailureThreshold(1), breaker.WithClock(s.clock))
+
+	err := c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+
+	s.Same(errTest, err)
+	s.False(breaker.IsTrip(err))
+}
+
+func (s *BreakerSuite) TestNamespace_QualifiesNameInHooksAndErrors() {
+	var hookNames []string
+	c := breaker.New("database",
+		breaker.WithNamespace("billing"),
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+		breaker.OnStateChange(func(name string, from, to breaker.State) {
+			hookNames = append(hookNames, name)
+		}),
+		breaker.OnReject(func(name string) {
+			hookNames = append(hookNames, name)
+		}),
+	)
+
+	s.Equal("database", c.Name())
+	s.Equal("billing:database", c.FullName())
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	err := c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	})
+
+	s.Equal([]string{"billing:database", "billing:database"}, hookNames)
+	var openErr *breaker.OpenError
+	s.Require().ErrorAs(err, &openErr)
+	s.Equal("billing:database", openErr.Name)
+	s.Equal("billing:database", c.Snapshot().Name)
+}
+
+func (s *BreakerSuite) TestNamespace_FullNameDefaultsToName() {
+	c := breaker.New("database", breaker.WithClock(s.clock))
+
+	s.Equal("database", c.FullName())
+}
+
+func (s *BreakerSuite) TestCircuitID_CarriedByErrorsSnapshotAndEvents() {
+	var events []breaker.CircuitEvent
+	c := breaker.New("database",
+		breaker.WithCircuitID("db-7"),
+		breaker.WithFailureThreshold(1),
+		breaker.WithRichErrors(),
+		breaker.WithClock(s.clock),
+		breaker.WithObserver(breaker.ObserverFunc(func(e breaker.CircuitEvent) {
+			events = append(events, e)
+		})),
+	)
+
+	err := c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	var circuitErr *breaker.CircuitError
+	s.Require().ErrorAs(err, &circuitErr)
+	s.Equal("db-7", circuitErr.ID)
+
+	err = c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	})
+	var openErr *breaker.OpenError
+	s.Require().ErrorAs(err, &openErr)
+	s.Equal("db-7", openErr.ID)
+
+	s.Equal("db-7", c.ID())
+	s.Equal("db-7", c.Snapshot().ID)
+	s.Require().NotEmpty(events)
+	for _, e := range events {
+		s.Equal("db-7", e.ID, e.Kind.String())
+	}
+}
+
+func (s *BreakerSuite) TestCircuitID_DefaultsToRandomUUID() {
+	a := breaker.New("database", breaker.WithClock(s.clock))
+	b := breaker.New("database", breaker.WithClock(s.clock))
+
+	s.Regexp(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, a.ID())
+	s.NotEqual(a.ID(), b.ID())
+}
+
+// startBlockingTrial starts a half-open trial at priority p that runs until
+// its context is cancelled or release is closed, and returns a channel
+// that receives the trial's error.
+func (s *BreakerSuite) startBlockingTrial(c *breaker.Circuit, p int, release <-chan struct{}) <-chan error {
+	started := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Do(breaker.WithCallPriority(context.Background(), p), func(ctx context.Context) error {
+			close(started)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-release:
+				return nil
+			}
+		})
+	}()
+	<-started
+	return done
+}
+
+func (s *BreakerSuite) newPriorityCircuit(opts ...breaker.Option) *breaker.Circuit {
+	c := breaker.New("test", append([]breaker.Option{
+		breaker.WithFailureThreshold(1),
+		breaker.WithSuccessThreshold(1),
+		breaker.WithHalfOpenRequests(1),
+		breaker.WithClock(s.clock),
+	}, opts...)...)
+	c.ForceOpen()
+	s.clock.Advance(breaker.DefaultOpenDuration)
+	return c
+}
+
+func (s *BreakerSuite) TestPriorityQueue_HigherPriorityDisplacesTrial() {
+	var reasons []breaker.RejectReason
+	c := s.newPriorityCircuit(
+		breaker.WithPriorityQueue(),
+		breaker.OnRejectReason(func(name string, reason breaker.RejectReason) {
+			reasons = append(reasons, reason)
+		}),
+	)
+	low := s.startBlockingTrial(c, 0, nil)
+
+	err := c.Do(breaker.WithCallPriority(context.Background(), 10), func(ctx context.Context) error {
+		return nil
+	})
+	s.NoError(err)
+	s.Equal(breaker.Closed, c.State())
+
+	s.True(breaker.IsOpen(<-low), "expected the displaced trial to be rejected")
+	s.Equal([]breaker.RejectReason{breaker.ReasonHalfOpenBudget}, reasons)
+	s.Equal(int64(1), c.Counts().TotalRejections)
+}
+
+func (s *BreakerSuite) TestPriorityQueue_EqualOrLowerPriorityIsRejected() {
+	c := s.newPriorityCircuit(breaker.WithPriorityQueue())
+	release := make(chan struct{})
+	running := s.startBlockingTrial(c, 5, release)
+
+	for _, p := range []int{5, 1} {
+		err := c.Do(breaker.WithCallPriority(context.Background(), p), func(ctx context.Context) error {
+			return nil
+		})
+		s.True(breaker.IsOpen(err), "priority %d", p)
+	}
+
+	close(release)
+	s.NoError(<-running)
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *BreakerSuite) TestPriorityQueue_PriorityIgnoredWithoutOption() {
+	c := s.newPriorityCircuit()
+	release := make(chan struct{})
+	running := s.startBlockingTrial(c, 0, release)
+
+	err := c.Do(breaker.WithCallPriority(context.Background(), 10), func(ctx context.Context) error {
+		return nil
+	})
+	s.True(breaker.IsOpen(err))
+
+	close(release)
+	s.NoError(<-running)
+}
+
+func (s *BreakerSuite) TestForceAllow_RunsRejectedCallWithoutRecording() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+	c.ForceOpen()
+
+	called := false
+	err := c.Do(breaker.WithForceAllow(context.Background()), func(ctx context.Context) error {
+		called = true
+		return errTest
+	})
+
+	s.ErrorIs(err, errTest)
+	s.True(called)
+	s.Equal(breaker.Open, c.State())
+	s.Equal(int64(1), c.Counts().TotalCalls)
+	s.Zero(c.Counts().TotalRejections)
+}
+
+func (s *BreakerSuite) TestForceFail_RecordsFailureWithoutCallingFn() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(2),
+		breaker.If(func(error) bool { return false }),
+		breaker.WithClock(s.clock),
+	)
+	ctx := breaker.WithForceFail(context.Background())
+
+	for range 2 {
+		called := false
+		err := c.Do(ctx, func(ctx context.Context) error {
+			called = true
+			return nil
+		})
+		s.ErrorIs(err, breaker.ErrForcedFailure)
+		s.False(called)
+	}
+
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestForceFail_StillRejectedWhenOpen() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+	c.ForceOpen()
+
+	err := c.Do(breaker.WithForceFail(context.Background()), func(ctx context.Context) error {
+		return nil
+	})
+	s.True(breaker.IsOpen(err))
+
+	err = c.Do(breaker.WithForceAllow(breaker.WithForceFail(context.Background())), func(ctx context.Context) error {
+		return nil
+	})
+	s.ErrorIs(err, breaker.ErrForcedFailure)
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestCounts_TracksFailures() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(10),
+		breaker.WithClock(s.clock),
+	)
+
+	for range 3 {
+		s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		}), errTest)
+	}
+
+	counts := c.Counts()
+	s.Equal(3, counts.Failures)
+	s.Zero(counts.Successes)
+}
+
+func (s *BreakerSuite) TestCounts_TracksSuccessesInHalfOpen() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithSuccessThreshold(5),
+		breaker.WithHalfOpenRequests(5),
+		breaker.WithOpenDuration(10*time.Second),
+		breaker.WithClock(s.clock),
+	)
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+	s.clock.Advance(11 * time.Second)
+
+	for range 3 {
+		s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+			return nil
+		}))
+	}
+
+	successes := c.Counts().Successes
+	s.Equal(3, successes)
+}
+
+func (s *BreakerSuite) TestWarmup_FailuresDoNotOpenDuringWarmup() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(2),
+		breaker.WithWarmup(time.Minute),
+		breaker.WithClock(s.clock),
+	)
+
+	for range 10 {
+		s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		}), errTest)
+	}
+
+	s.Equal(breaker.Closed, c.State(), "expected Closed during warm-up")
+
+	failures := c.Counts().Failures
+	s.Equal(10, failures, "expected failures to be recorded during warm-up")
+
+	s.clock.Advance(time.Minute)
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+
+	s.Equal(breaker.Open, c.State(), "expected Open after warm-up expires")
+}
+
+func (s *BreakerSuite) TestStatePredicates_ReflectCurrentState() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(10*time.Second),
+		breaker.WithClock(s.clock),
+	)
+
+	s.True(c.IsClosed())
+	s.False(c.IsOpen())
+	s.False(c.IsHalfOpen())
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+
+	s.False(c.IsClosed())
+	s.True(c.IsOpen())
+	s.False(c.IsHalfOpen())
+
+	s.clock.Advance(11 * time.Second)
+
+	s.False(c.IsClosed())
+	s.False(c.IsOpen())
+	s.True(c.IsHalfOpen())
+}
+
+func (s *BreakerSuite) TestWarmupCalls_DiscardsFirstCalls() {
+	var calls int
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithWarmupCalls(3),
+		breaker.WithClock(s.clock),
+		breaker.OnCall(func(name string, state breaker.State, err error) {
+			calls++
+		}),
+	)
+
+	for range 3 {
+		s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		}), errTest)
+	}
+
+	s.Equal(breaker.Closed, c.State())
+	s.Zero(c.Snapshot().Failures)
+	s.Zero(c.Snapshot().WarmupRemaining)
+	s.Equal(3, calls, "expected hooks to fire during warm-up")
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestWarmupCalls_RestartsOnReset() {
+	c := breaker.New("test",
+		breaker.WithWarmupCalls(2),
+		breaker.WithClock(s.clock),
+	)
+
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+	s.Equal(1, c.Snapshot().WarmupRemaining)
+
+	c.ResetAndReport()
+
+	s.Equal(2, c.Snapshot().WarmupRemaining)
+}
+
+func (s *BreakerSuite) TestWarmupCalls_RecordsTrialsWhenStartingNotClosed() {
+	tests := map[string]breaker.State{
+		"half-open": breaker.HalfOpen,
+		"open":      breaker.Open,
+	}
+
+	for name, initial := range tests {
+		s.Run(name, func() {
+			c := breaker.New("test",
+				breaker.WithWarmupCalls(1),
+				breaker.WithSuccessThreshold(1),
+				breaker.WithOpenDuration(time.Minute),
+				breaker.WithInitialState(initial, s.clock.Now()),
+				breaker.WithClock(s.clock),
+			)
+			s.clock.Advance(time.Minute)
+
+			s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+				return errTest
+			}), errTest)
+			s.Equal(breaker.Open, c.State(), "expected the failed trial to reopen the circuit")
+
+			s.clock.Advance(time.Minute)
+			s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+				return nil
+			}))
+			s.Equal(breaker.Closed, c.State())
+			s.Equal(1, c.Snapshot().WarmupRemaining, "expected warm-up to apply once closed")
+		})
+	}
+}
+
+func (s *BreakerSuite) TestWarmupCalls_RecordsTrialsAfterRestoreState() {
+	source := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+	_ = source.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	data, err := source.MarshalState()
+	s.Require().NoError(err)
+
+	c := breaker.New("test",
+		breaker.WithWarmupCalls(1),
+		breaker.WithSuccessThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithClock(s.clock),
+	)
+	s.Require().NoError(c.RestoreState(data))
+	s.clock.Advance(time.Minute)
+
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *BreakerSuite) TestDrain_ReturnsImmediatelyWithNoInFlightCalls() {
+	c := breaker.New("test",
+		breaker.WithGracefulDrain(0),
+		breaker.WithClock(s.clock),
+	)
+
+	s.NoError(c.Drain(context.Background()))
+
+	err := c.Do(context.Background(), func(ctx context.Context) error {
+		s.Fail("function should not be called while draining")
+		return nil
+	})
+	s.ErrorIs(err, breaker.ErrDraining)
+	s.False(breaker.IsOpen(err))
+}
+
+func (s *BreakerSuite) TestDrain_WaitsForInFlightCalls() {
+	c := breaker.New("test",
+		breaker.WithGracefulDrain(0),
+		breaker.WithClock(s.clock),
+	)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	s.Equal(1, c.InFlight())
+
+	drained := make(chan error, 1)
+	go func() {
+		drained <- c.Drain(context.Background())
+	}()
+
+	select {
+	case <-drained:
+		s.Fail("Drain returned before in-flight call completed")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+
+	s.NoError(<-drained)
+	s.Zero(c.InFlight())
+}
+
+func (s *BreakerSuite) TestDrain_ReturnsContextErrorWhenCancelled() {
+	c := breaker.New("test",
+		breaker.WithGracefulDrain(0),
+		breaker.WithClock(s.clock),
+	)
+
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	go func() {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s.ErrorIs(c.Drain(ctx), context.Canceled)
+}
+
+func (s *BreakerSuite) TestDrain_RespectsTimeout() {
+	c := breaker.New("test",
+		breaker.WithGracefulDrain(10*time.Millisecond),
+		breaker.WithClock(s.clock),
+	)
+
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	go func() {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	s.ErrorIs(c.Drain(context.Background()), context.DeadlineExceeded)
+}
+
+func (s *BreakerSuite) TestInFlight_TracksExecutingCalls() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+
+	s.Zero(c.InFlight())
+
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		s.Equal(1, c.InFlight())
+		s.Equal(1, c.Snapshot().InFlight)
+		return nil
+	}))
+
+	s.Zero(c.InFlight())
+}
+
+func (s *BreakerSuite) TestInFlight_NotLeakedWhenRejected() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+
+	for range 3 {
+		s.True(breaker.IsOpen(c.Do(context.Background(), func(ctx context.Context) error {
+			return nil
+		})))
+	}
+
+	s.Zero(c.InFlight())
+}
+
+func (s *BreakerSuite) TestActiveProbe_MovesOpenToHalfOpenOnSuccess() {
+	clock := breakertesting.NewClock()
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Hour),
+		breaker.WithActiveProbe(5*time.Second, func(ctx context.Context) error {
+			return nil
+		}),
+		breaker.WithClock(clock),
+	)
+	defer c.Close()
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+
+	clock.Advance(4 * time.Second)
+	s.Equal(breaker.Open, c.State())
+
+	clock.Advance(time.Second)
+	s.Equal(breaker.HalfOpen, c.State())
+}
+
+func (s *BreakerSuite) TestActiveProbe_KeepsOpenOnFailure() {
+	var probes atomic.Int32
+	clock := breakertesting.NewClock()
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Hour),
+		breaker.WithActiveProbe(5*time.Second, func(ctx context.Context) error {
+			probes.Add(1)
+			return errTest
+		}),
+		breaker.WithClock(clock),
+	)
+	defer c.Close()
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+
+	clock.Advance(5 * time.Second)
+	clock.Advance(5 * time.Second)
+
+	s.Equal(int32(2), probes.Load())
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestActiveProbe_DoesNotProbeWhenClosed() {
+	var probes atomic.Int32
+	clock := breakertesting.NewClock()
+	c := breaker.New("test",
+		breaker.WithActiveProbe(time.Second, func(ctx context.Context) error {
+			probes.Add(1)
+			return nil
+		}),
+		breaker.WithClock(clock),
+	)
+
+	for range 3 {
+		clock.Advance(time.Second)
+	}
+	s.NoError(c.Close())
+
+	s.Zero(probes.Load())
+}
+
+func (s *BreakerSuite) TestActiveProbe_StopsOnClose() {
+	var probes atomic.Int32
+	clock := breakertesting.NewClock()
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Hour),
+		breaker.WithActiveProbe(time.Second, func(ctx context.Context) error {
+			probes.Add(1)
+			return errTest
+		}),
+		breaker.WithClock(clock),
+	)
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+
+	clock.Advance(time.Second)
+	s.NoError(c.Close())
+	clock.Advance(time.Second)
+
+	s.Equal(int32(1), probes.Load())
+}
+
+func (s *BreakerSuite) TestClose_IsSafeToCallTwice() {
+	c := breaker.New("test",
+		breaker.WithActiveProbe(time.Millisecond, func(ctx context.Context) error {
+			return nil
+		}),
+		breaker.WithClock(s.clock),
+	)
+
+	s.NoError(c.Close())
+	s.NoError(c.Close())
+}
+
+func (s *BreakerSuite) TestClose_RejectsSubsequentCalls() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+
+	s.NoError(c.Close())
+
+	err := c.Do(context.Background(), func(ctx context.Context) error {
+		s.Fail("function should not be called after Close")
+		return nil
+	})
+	s.ErrorIs(err, breaker.ErrShutdown)
+	s.False(breaker.IsOpen(err))
+}
+
+func (s *BreakerSuite) TestDisabled_PassesThroughWithoutTracking() {
+	var hooks int
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithDisabled(),
+		breaker.WithClock(s.clock),
+		breaker.OnCall(func(name string, state breaker.State, err error) {
+			hooks++
+		}),
+		breaker.OnStateChange(func(name string, from, to breaker.State) {
+			hooks++
+		}),
+	)
+
+	for range 3 {
+		s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		}), errTest)
+	}
+
+	s.Equal(breaker.Closed, c.State())
+	counts := c.Counts()
+	s.Zero(counts.Failures)
+	s.Zero(counts.Successes)
+	s.Zero(hooks)
+}
+
+func (s *BreakerSuite) TestDisabled_EnableResumesTracking() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithDisabled(),
+		breaker.WithClock(s.clock),
+	)
+
+	c.Enable()
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestDisabled_DisableClearsOpenCircuit() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+	s.Equal(breaker.Open, c.State())
+
+	c.Disable()
+
+	s.Equal(breaker.Closed, c.State())
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+}
+
+func (s *BreakerSuite) TestDisabled_DisableReportsStateChange() {
+	var transitions [][2]breaker.State
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+		breaker.OnStateChange(func(name string, from, to breaker.State) {
+			transitions = append(transitions, [2]breaker.State{from, to})
+		}),
+	)
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+	events, unsubscribe, err := c.Subscribe()
+	s.Require().NoError(err)
+	defer unsubscribe()
+
+	c.Disable()
+
+	s.Equal([][2]breaker.State{
+		{breaker.Closed, breaker.Open},
+		{breaker.Open, breaker.Closed},
+	}, transitions)
+	select {
+	case e := <-events:
+		s.Equal(breaker.Closed, e.To)
+	case <-time.After(time.Second):
+		s.Fail("subscriber did not see the transition")
+	}
+	s.NoError(c.Err())
+}
+
+func (s *BreakerSuite) TestDisabled_Enabled() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+	s.True(c.Enabled())
+
+	c.Disable()
+	s.False(c.Enabled())
+
+	c.Enable()
+	s.True(c.Enabled())
+
+	s.False(breaker.New("test", breaker.WithDisabled()).Enabled())
+}
+
+func (s *BreakerSuite) TestWait_ReturnsImmediatelyWhenClosed() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+
+	s.NoError(c.Wait(context.Background()))
+}
+
+func (s *BreakerSuite) TestWait_BlocksUntilClosed() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Wait(context.Background())
+	}()
+
+	select {
+	case <-done:
+		s.Fail("Wait returned while circuit was open")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	c.ResetAndReport()
+
+	s.NoError(<-done)
+}
+
+func (s *BreakerSuite) TestWait_ReturnsContextError() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	s.ErrorIs(c.Wait(ctx), context.DeadlineExceeded)
+}
+
+func TestWaitForState_WakesOnOpenDurationExpiry(t *testing.T) {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(20*time.Millisecond),
+	)
+
+	require.ErrorIs(t, c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	require.NoError(t, c.WaitForState(ctx, breaker.HalfOpen))
+	require.Equal(t, breaker.HalfOpen, c.State())
+}
+
+func (s *BreakerSuite) TestDryRun_NeverRejectsButTracksState() {
+	var transitions []breaker.State
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(2),
+		breaker.WithDryRun(true),
+		breaker.WithClock(s.clock),
+		breaker.OnStateChange(func(name string, from, to breaker.State) {
+			transitions = append(transitions, to)
+		}),
+	)
+
+	for range 5 {
+		s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		}), errTest)
+	}
+
+	snap := c.Snapshot()
+	s.Equal(breaker.Open, snap.State, "expected would-be state to be open")
+	s.True(snap.DryRun)
+	s.Equal([]breaker.State{breaker.Open}, transitions)
+
+	called := false
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		called = true
+		return nil
+	}))
+	s.True(called)
+}
+
+func (s *BreakerSuite) TestDryRun_RecoversThroughHalfOpen() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithSuccessThreshold(2),
+		breaker.WithOpenDuration(10*time.Second),
+		breaker.WithDryRun(true),
+		breaker.WithClock(s.clock),
+	)
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+	s.clock.Advance(11 * time.Second)
+
+	for range 2 {
+		s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+			return nil
+		}))
+	}
+
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *BreakerSuite) TestProbeInterval_SpacesHalfOpenCalls() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithSuccessThreshold(3),
+		breaker.WithHalfOpenRequests(3),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithProbeInterval(time.Second),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(time.Minute)
+
+	succeed := func(ctx context.Context) error { return nil }
+	s.NoError(c.Do(context.Background(), succeed))
+	s.True(breaker.IsOpen(c.Do(context.Background(), succeed)))
+
+	s.clock.Advance(500 * time.Millisecond)
+	s.True(breaker.IsOpen(c.Do(context.Background(), succeed)))
+
+	s.clock.Advance(500 * time.Millisecond)
+	s.NoError(c.Do(context.Background(), succeed))
+
+	s.clock.Advance(time.Second)
+	s.NoError(c.Do(context.Background(), succeed))
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *BreakerSuite) TestProbeInterval_DoesNotRaiseHalfOpenLimit() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithSuccessThreshold(5),
+		breaker.WithHalfOpenRequests(2),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithProbeInterval(time.Second),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(time.Minute)
+
+	succeed := func(ctx context.Context) error { return nil }
+	s.NoError(c.Do(context.Background(), succeed))
+	s.clock.Advance(time.Second)
+	s.NoError(c.Do(context.Background(), succeed))
+	s.clock.Advance(time.Second)
+	s.True(breaker.IsOpen(c.Do(context.Background(), succeed)))
+}
+
+func (s *BreakerSuite) TestRecoveryRamp_CapsConcurrencyAfterRecovery() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithSuccessThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithRecoveryRamp(10*time.Second),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(time.Minute)
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+	s.Require().Equal(breaker.Closed, c.State())
+
+	// concurrent makes depth calls nested inside one another, so that depth
+	// calls are in flight at once, and returns the first rejection. Outer
+	// calls succeed regardless, so a rejection does not trip the circuit.
+	var concurrent func(depth int) error
+	concurrent = func(depth int) error {
+		var innerErr error
+		err := c.Do(context.Background(), func(ctx context.Context) error {
+			if depth > 1 {
+				innerErr = concurrent(depth - 1)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return innerErr
+	}
+
+	s.NoError(concurrent(1))
+	s.True(breaker.IsOpen(concurrent(2)))
+
+	s.clock.Advance(time.Second)
+	s.NoError(concurrent(2))
+	s.True(breaker.IsOpen(concurrent(3)))
+
+	s.clock.Advance(9 * time.Second)
+	s.NoError(concurrent(50))
+}
+
+func (s *BreakerSuite) TestRecoveryRamp_NotAppliedAfterManualReset() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithRecoveryRamp(10*time.Second),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	c.ResetAndReport()
+
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return c.Do(ctx, func(ctx context.Context) error {
+			return nil
+		})
+	}))
+}
+
+func (s *BreakerSuite) TestHalfOpenProbeTimeout_ReopensOnHangingProbe() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithHalfOpenProbeTimeout(10*time.Millisecond),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(time.Minute)
+
+	err := c.Do(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	s.ErrorIs(err, context.DeadlineExceeded)
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestHalfOpenProbeTimeout_OnlyAppliesInHalfOpen() {
+	c := breaker.New("test",
+		breaker.WithHalfOpenProbeTimeout(10*time.Millisecond),
+		breaker.WithClock(s.clock),
+	)
+
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		_, ok := ctx.Deadline()
+		s.False(ok)
+		return nil
+	}))
+}
+
+func (s *BreakerSuite) TestProbe_RunsBeforeHalfOpenCall() {
+	var order []string
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithSuccessThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithProbe(func(ctx context.Context) error {
+			order = append(order, "probe")
+			return nil
+		}),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		order = append(order, "trip")
+		return errTest
+	})
+	s.clock.Advance(time.Minute)
+
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		order = append(order, "call")
+		return nil
+	}))
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		order = append(order, "call")
+		return nil
+	}))
+
+	s.Equal([]string{"trip", "probe", "call", "call"}, order)
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *BreakerSuite) TestProbe_FailureReopensWithoutCalling() {
+	probeErr := errors.New("health check failed")
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithProbe(func(ctx context.Context) error {
+			return probeErr
+		}),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(time.Minute)
+
+	err := c.Do(context.Background(), func(ctx context.Context) error {
+		s.Fail("function should not be called when the probe fails")
+		return nil
+	})
+
+	s.True(breaker.IsOpen(err))
+	s.ErrorIs(err, probeErr)
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BreakerSuite) TestProbe_ErrorsSubjectToCondition() {
+	errNotFound := errors.New("not found")
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithSuccessThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.If(func(err error) bool {
+			return err != nil && !errors.Is(err, errNotFound)
+		}),
+		breaker.WithProbe(func(ctx context.Context) error {
+			return errNotFound
+		}),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(time.Minute)
+
+	called := false
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		called = true
+		return nil
+	}))
+	s.True(called)
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *BreakerSuite) TestHalfOpenRatio_AdmitsFractionOfCalls() {
+	rolls := []float64{0.9, 0.1, 0.5, 0.2}
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithSuccessThreshold(10),
+		breaker.WithHalfOpenRequests(100),
+		breaker.WithHalfOpenRatio(0.25),
+		breaker.WithOpenDuration(10*time.Second),
+		breaker.WithRand(func() float64 {
+			r := rolls[0]
+			rolls = rolls[1:]
+			return r
+		}),
+		breaker.WithClock(s.clock),
+	)
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+	s.clock.Advance(11 * time.Second)
+
+	var admitted []bool
+	for range 4 {
+		err := c.Do(context.Background(), func(ctx context.Context) error {
+			return nil
+		})
+		admitted = append(admitted, !breaker.IsOpen(err))
+	}
+
+	s.Equal([]bool{false, true, false, true}, admitted)
+}
+
+func (s *BreakerSuite) TestHalfOpenRatio_ClosesAfterSuccessThreshold() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithSuccessThreshold(2),
+		breaker.WithHalfOpenRequests(2),
+		breaker.WithHalfOpenRatio(0.5),
+		breaker.WithOpenDuration(10*time.Second),
+		breaker.WithRand(func() float64 { return 0 }),
+		breaker.WithClock(s.clock),
+	)
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+	s.clock.Advance(11 * time.Second)
+
+	for range 2 {
+		s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+			return nil
+		}))
+	}
+
+	s.Equal(breaker.Closed, c.State())
+}
+
+func TestSetDefaultClock(t *testing.T) {
+	clock := newFakeClock()
+	breaker.SetDefaultClock(clock)
+	t.Cleanup(func() { breaker.SetDefaultClock(nil) })
+	require.Same(t, clock, breaker.DefaultClock())
+
+	c := breaker.New("test", breaker.WithFailureThreshold(1), breaker.WithOpenDuration(time.Minute))
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	require.Equal(t, clock.Now(), c.Snapshot().OpenedAt)
+
+	clock.Advance(time.Minute)
+	require.Equal(t, breaker.HalfOpen, c.State(), "expected the circuit to use the default clock")
+
+	other := newFakeClock()
+	withClock := breaker.New("test", breaker.WithClock(other))
+	require.Equal(t, other.Now(), withClock.Snapshot().At, "expected WithClock to override the default")
+}
+
+func TestSetDefaultClock_NilRestoresRealClock(t *testing.T) {
+	breaker.SetDefaultClock(newFakeClock())
+	breaker.SetDefaultClock(nil)
+
+	c := breaker.New("test")
+
+	require.WithinDuration(t, time.Now(), c.Snapshot().At, time.Minute)
+}
+
+func TestWithClockFunc(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithClockFunc(func() time.Time { return now }),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	require.Equal(t, now, c.Snapshot().OpenedAt)
+	require.Equal(t, breaker.Open, c.State())
+
+	now = now.Add(time.Minute)
+	require.Equal(t, breaker.HalfOpen, c.State(), "expected the circuit to read time from the function")
+}
+
+// nowOnlyClock is a Clock without AfterFunc.
+type nowOnlyClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *nowOnlyClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func TestWithClock_NowOnlyClockUsesRealTimers(t *testing.T) {
+	clock := &nowOnlyClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Hour),
+		breaker.WithAutoReset(10*time.Millisecond),
+		breaker.WithClock(clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	require.Equal(t, clock.Now(), c.Snapshot().OpenedAt)
+	require.Equal(t, breaker.Open, c.State())
+
+	require.Eventually(t, func() bool {
+		return c.State() == breaker.Closed
+	}, 5*time.Second, 5*time.Millisecond, "expected WithAutoReset to fire on a real timer")
+}
+
+func TestRealTimers_MakesTimerClock(t *testing.T) {
+	var clock struct {
+		nowOnlyClock
+		breaker.RealTimers
+	}
+	var _ breaker.TimerClock = &clock
+
+	fired := make(chan struct{})
+	clock.AfterFunc(time.Millisecond, func() { close(fired) })
+
+	select {
+	case <-fired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected AfterFunc to fire")
+	}
+}
+
+func TestConditionHelpers(t *testing.T) {
+	errTimeout := errors.New("timeout")
+	wrapped := fmt.Errorf("call: %w", fmt.Errorf("rpc: %w", errTimeout))
+	tests := map[string]struct {
+		cond breaker.Condition
+		err  error
+		want bool
+	}{
+		"IsErr matches deeply wrapped": {cond: breaker.IsErr(errTimeout), err: wrapped, want: true},
+		"IsErr rejects other error":    {cond: breaker.IsErr(errTimeout), err: errTest, want: false},
+		"IsErr rejects nil":            {cond: breaker.IsErr(errTimeout), err: nil, want: false},
+		"IsType matches wrapped type":  {cond: breaker.IsType[*breaker.PanicError](), err: fmt.Errorf("x: %w", &breaker.PanicError{Value: 1}), want: true},
+		"IsType rejects other type":    {cond: breaker.IsType[*breaker.PanicError](), err: errTest, want: false},
+		"AnyOf matches one":            {cond: breaker.AnyOf(breaker.IsErr(errTest), breaker.IsErr(errTimeout)), err: wrapped, want: true},
+		"AnyOf rejects none":           {cond: breaker.AnyOf(breaker.IsErr(errTest)), err: wrapped, want: false},
+		"AnyOf empty matches nothing":  {cond: breaker.AnyOf(), err: errTest, want: false},
+		"AllOf matches all":            {cond: breaker.AllOf(breaker.IsErr(errTimeout), breaker.Not(breaker.IsErr(errTest))), err: wrapped, want: true},
+		"AllOf rejects partial":        {cond: breaker.AllOf(breaker.IsErr(errTimeout), breaker.IsErr(errTest)), err: wrapped, want: false},
+		"AllOf empty matches anything": {cond: breaker.AllOf(), err: errTest, want: true},
+		"composes with Not":            {cond: breaker.Not(breaker.AnyOf(breaker.IsErr(errTimeout))), err: wrapped, want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.cond(tc.err))
+		})
+	}
+}
+
+func TestAnyOfAllOf_ShortCircuit(t *testing.T) {
+	tests := map[string]struct {
+		combine func(...breaker.Condition) breaker.Condition
+		results []bool
+		want    bool
+		calls   int
+	}{
+		"AnyOf stops at first match":    {combine: breaker.AnyOf, results: []bool{false, true, true}, want: true, calls: 2},
+		"AnyOf evaluates all on miss":   {combine: breaker.AnyOf, results: []bool{false, false, false}, want: false, calls: 3},
+		"AllOf stops at first mismatch": {combine: breaker.AllOf, results: []bool{true, false, true}, want: false, calls: 2},
+		"AllOf evaluates all on match":  {combine: breaker.AllOf, results: []bool{true, true, true}, want: true, calls: 3},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			calls := 0
+			conds := make([]breaker.Condition, len(tc.results))
+			for i, result := range tc.results {
+				conds[i] = func(err error) bool {
+					calls++
+					return result
+				}
+			}
+
+			require.Equal(t, tc.want, tc.combine(conds...)(errTest))
+			require.Equal(t, tc.calls, calls)
+		})
+	}
+}
+
+func TestIsOpen(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want bool
+	}{
+		"returns true for ErrOpen":              {err: breaker.ErrOpen, want: true},
+		"returns true for ErrCallLimitExceeded": {err: breaker.ErrCallLimitExceeded, want: true},
+		"returns false for other error":         {err: errTest, want: false},
+		"returns false for nil":                 {err: nil, want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, breaker.IsOpen(tc.err))
+		})
+	}
+}
+
+func TestIsDraining(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want bool
+	}{
+		"returns true for ErrDraining":  {err: breaker.ErrDraining, want: true},
+		"returns false for ErrOpen":     {err: breaker.ErrOpen, want: false},
+		"returns false for other error": {err: errTest, want: false},
+		"returns false for nil":         {err: nil, want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, breaker.IsDraining(tc.err))
+		})
+	}
+}
+
+// invalidConfigs lists option sets that fail validation, keyed by
+// description, along with the ConfigError field they report.
+var invalidConfigs = map[string]struct {
+	opts  []breaker.Option
+	field string
+}{
+	"zero failure threshold":    {opts: []breaker.Option{breaker.WithFailureThreshold(0)}, field: "FailureThreshold"},
+	"zero success threshold":    {opts: []breaker.Option{breaker.WithSuccessThreshold(0)}, field: "SuccessThreshold"},
+	"negative open duration":    {opts: []breaker.Option{breaker.WithOpenDuration(-time.Second)}, field: "OpenDuration"},
+	"negative window":           {opts: []breaker.Option{breaker.WithWindow(-time.Second)}, field: "Window"},
+	"zero half-open requests":   {opts: []breaker.Option{breaker.WithHalfOpenRequests(0)}, field: "HalfOpenRequests"},
+	"negative probe interval":   {opts: []breaker.Option{breaker.WithProbeInterval(-time.Second)}, field: "ProbeInterval"},
+	"negative recovery ramp":    {opts: []breaker.Option{breaker.WithRecoveryRamp(-time.Second)}, field: "RecoveryRamp"},
+	"negative probe timeout":    {opts: []breaker.Option{breaker.WithHalfOpenProbeTimeout(-time.Second)}, field: "HalfOpenProbeTimeout"},
+	"negative max half-open":    {opts: []breaker.Option{breaker.WithMaxHalfOpenDuration(-time.Second)}, field: "MaxHalfOpenDuration"},
+	"negative velocity":         {opts: []breaker.Option{breaker.WithVelocityThreshold(-1, time.Second)}, field: "VelocityThreshold"},
+	"velocity without window":   {opts: []breaker.Option{breaker.WithVelocityThreshold(5, 0)}, field: "VelocityThreshold"},
+	"negative cooldown":         {opts: []breaker.Option{breaker.WithCooldown(-time.Second)}, field: "Cooldown"},
+	"negative call limit":       {opts: []breaker.Option{breaker.WithCallLimit(-1)}, field: "CallLimit"},
+	"negative latency window":   {opts: []breaker.Option{breaker.WithLatencyWindow(-1)}, field: "LatencyWindow"},
+	"negative min closed":       {opts: []breaker.Option{breaker.WithMinClosedDuration(-time.Second)}, field: "MinClosedDuration"},
+	"negative event buffer":     {opts: []breaker.Option{breaker.WithEventBuffer(-1)}, field: "EventBuffer"},
+	"negative warmup":           {opts: []breaker.Option{breaker.WithWarmup(-time.Second)}, field: "Warmup"},
+	"negative warmup calls":     {opts: []breaker.Option{breaker.WithWarmupCalls(-1)}, field: "WarmupCalls"},
+	"negative auto reset":       {opts: []breaker.Option{breaker.WithAutoReset(-time.Second)}, field: "AutoReset"},
+	"negative drain timeout":    {opts: []breaker.Option{breaker.WithGracefulDrain(-time.Second)}, field: "GracefulDrain"},
+	"non-positive probe period": {opts: []breaker.Option{breaker.WithActiveProbe(0, func(context.Context) error { return nil })}, field: "ActiveProbe"},
+	"nil clock":                 {opts: []breaker.Option{breaker.WithClock(nil)}, field: "Clock"},
+	"nil clock func":            {opts: []breaker.Option{breaker.WithClockFunc(nil)}, field: "Clock"},
+	"negative half-open ratio":  {opts: []breaker.Option{breaker.WithHalfOpenRatio(-0.1)}, field: "HalfOpenRatio"},
+	"half-open ratio above one": {opts: []breaker.Option{breaker.WithHalfOpenRatio(1.5)}, field: "HalfOpenRatio"},
+	"negative history":          {opts: []breaker.Option{breaker.WithHistory(-1)}, field: "History"},
+	"negative listener limit":   {opts: []breaker.Option{breaker.WithMaxStateChangeListeners(-1)}, field: "MaxStateChangeListeners"},
+	"negative sampling rate":    {opts: []breaker.Option{breaker.WithSamplingRate(-0.1)}, field: "SamplingRate"},
+	"sampling rate above one":   {opts: []breaker.Option{breaker.WithSamplingRate(1.5)}, field: "SamplingRate"},
+	"nil rand":                  {opts: []breaker.Option{breaker.WithRand(nil)}, field: "Rand"},
+	"unknown initial state":     {opts: []breaker.Option{breaker.WithInitialState(breaker.State(99), time.Time{})}, field: "InitialState"},
+	"future initial opened-at":  {opts: []breaker.Option{breaker.WithInitialState(breaker.Open, time.Now().Add(time.Hour))}, field: "InitialState"},
+}
+
+func TestMustNew(t *testing.T) {
+	for name, tc := range invalidConfigs {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				require.NotNil(t, r, "expected MustNew to panic")
+				cfgErr, ok := r.(*breaker.ConfigError)
+				require.True(t, ok, "expected *ConfigError, got %T", r)
+				require.Equal(t, tc.field, cfgErr.Field)
+				require.NotEmpty(t, cfgErr.Message)
+			}()
+			breaker.MustNew("test", tc.opts...)
+		})
+	}
+}
+
+func TestNewWithError(t *testing.T) {
+	for name, tc := range invalidConfigs {
+		t.Run(name, func(t *testing.T) {
+			c, err := breaker.NewWithError("test", tc.opts...)
+
+			require.Nil(t, c)
+			var cfgErr *breaker.ConfigError
+			require.ErrorAs(t, err, &cfgErr)
+			require.Equal(t, tc.field, cfgErr.Field)
+		})
+	}
+}
+
+func TestNewWithError_ValidConfig(t *testing.T) {
+	c, err := breaker.NewWithError("test", breaker.WithFailureThreshold(3))
+
+	require.NoError(t, err)
+	require.Equal(t, "test", c.Name())
+}
+
+func TestNew_FallsBackToDefaultsForInvalidConfig(t *testing.T) {
+	for name, tc := range invalidConfigs {
+		t.Run(name, func(t *testing.T) {
+			c := breaker.New("test", tc.opts...)
+
+			require.NotNil(t, c)
+			require.Equal(t, breaker.Closed, c.State())
+			require.NoError(t, c.Do(context.Background(), func(ctx context.Context) error {
+				return nil
+			}))
+		})
+	}
+}
+
+func TestMustNew_ValidConfig(t *testing.T) {
+	c := breaker.MustNew("test", breaker.WithFailureThreshold(3))
+
+	require.Equal(t, "test", c.Name())
+	require.Equal(t, breaker.Closed, c.State())
+}
+
+func TestConfigError_Error(t *testing.T) {
+	err := &breaker.ConfigError{Field: "FailureThreshold", Message: "must be at least 1"}
+
+	require.Equal(t, "breaker: invalid FailureThreshold: must be at least 1", err.Error())
+}
+
+func TestPanicError(t *testing.T) {
+	err := &breaker.PanicError{Value: errTest}
+
+	require.Equal(t, "breaker: panic: test error", err.Error())
+	require.ErrorIs(t, err, errTest)
+	require.NoError(t, (&breaker.PanicError{Value: "boom"}).Unwrap())
+}
+
+func TestOpenError(t *testing.T) {
+	err := &breaker.OpenError{Name: "test", Cause: io.ErrUnexpectedEOF}
+
+	require.Equal(t, "circuit open: unexpected EOF", err.Error())
+	require.ErrorIs(t, err, breaker.ErrOpen)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	require.True(t, breaker.IsOpen(err))
+	require.Equal(t, "circuit open", (&breaker.OpenError{Name: "test"}).Error())
+}
+
+func TestState_String(t *testing.T) {
+	tests := map[string]struct {
+		state breaker.State
+		want  string
+	}{
+		"closed":    {state: breaker.Closed, want: "closed"},
+		"open":      {state: breaker.Open, want: "open"},
+		"half-open": {state: breaker.HalfOpen, want: "half-open"},
+		"unknown":   {state: breaker.State(99), want: "unknown"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.state.String())
+		})
+	}
+}
+
+func TestState_Code(t *testing.T) {
+	tests := map[string]struct {
+		state breaker.State
+		want  int
+	}{
+		"closed":    {state: breaker.Closed, want: 0},
+		"half-open": {state: breaker.HalfOpen, want: 1},
+		"open":      {state: breaker.Open, want: 2},
+		"unknown":   {state: breaker.State(99), want: -1},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.state.Code())
+		})
+	}
+}
+
+func TestStateFromCode(t *testing.T) {
+	tests := map[string]struct {
+		code   int
+		want   breaker.State
+		wantOK bool
+	}{
+		"closed":    {code: 0, want: breaker.Closed, wantOK: true},
+		"half-open": {code: 1, want: breaker.HalfOpen, wantOK: true},
+		"open":      {code: 2, want: breaker.Open, wantOK: true},
+		"unknown":   {code: 7, want: breaker.Closed, wantOK: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, ok := breaker.StateFromCode(tc.code)
+			require.Equal(t, tc.wantOK, ok)
+			require.Equal(t, tc.want, got)
 		})
 	}
 }
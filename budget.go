@@ -0,0 +1,94 @@
+package breaker
+
+import (
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Budget is a failure budget shared by several circuits. Each member counts
+// its closed-state failures against the budget as well as against its own
+// threshold. When the budget's consecutive failures across all members
+// reach its limit, every member opens and then recovers independently.
+// A success in any closed member resets the budget. A circuit leaves the
+// budget when it is closed with Circuit.Close or removed with Remove. Safe
+// for concurrent use.
+//
+// Unlike a Composite, which aggregates member state for reads, a Budget
+// aggregates failures.
+type Budget struct {
+	limit int
+
+	mu       sync.Mutex
+	failures int
+	members  map[*Circuit]struct{}
+}
+
+// NewBudget creates a Budget that trips its members after limit
+// consecutive failures across all of them. A limit below 1 is treated as
+// 1.
+func NewBudget(limit int) *Budget {
+	return &Budget{
+		limit:   max(limit, 1),
+		members: make(map[*Circuit]struct{}),
+	}
+}
+
+// Failures returns the budget's current consecutive failure count.
+func (b *Budget) Failures() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures
+}
+
+// Remove takes c out of the budget: its failures and successes no longer
+// count against the budget, and it is not opened when the budget is
+// exhausted. Removing a circuit that is not a member does nothing.
+func (b *Budget) Remove(c *Circuit) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.members, c)
+}
+
+func (b *Budget) add(c *Circuit) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.members[c] = struct{}{}
+}
+
+// fail records a failure of member c and reports whether the budget is
+// exhausted. When exhausted, the count restarts for the next round.
+func (b *Budget) fail(c *Circuit) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.members[c]; !ok {
+		return false
+	}
+	b.failures++
+	if b.failures < b.limit {
+		return false
+	}
+	b.failures = 0
+	return true
+}
+
+// succeed records a success of member c, which resets the count.
+func (b *Budget) succeed(c *Circuit) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.members[c]; ok {
+		b.failures = 0
+	}
+}
+
+// trip opens every closed member that is not warming up. It must not be
+// called while holding any member's lock.
+func (b *Budget) trip() {
+	b.mu.Lock()
+	members := slices.Collect(maps.Keys(b.members))
+	b.mu.Unlock()
+
+	for _, c := range members {
+		c.trip()
+	}
+}
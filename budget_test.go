@@ -0,0 +1,171 @@
+package breaker_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bjaus/breaker"
+	"github.com/stretchr/testify/suite"
+)
+
+type BudgetSuite struct {
+	suite.Suite
+	clock *fakeClock
+}
+
+func TestBudgetSuite(t *testing.T) {
+	suite.Run(t, new(BudgetSuite))
+}
+
+func (s *BudgetSuite) SetupTest() {
+	s.clock = newFakeClock()
+}
+
+func (s *BudgetSuite) newMember(name string, b *breaker.Budget) *breaker.Circuit {
+	return breaker.New(name,
+		breaker.WithFailureThreshold(100),
+		breaker.WithBudget(b),
+		breaker.WithClock(s.clock),
+	)
+}
+
+func fail(c *breaker.Circuit) error {
+	return c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+}
+
+func succeed(c *breaker.Circuit) error {
+	return c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	})
+}
+
+func (s *BudgetSuite) TestExhaustedBudgetOpensEveryMember() {
+	b := breaker.NewBudget(3)
+	a, c, idle := s.newMember("a", b), s.newMember("c", b), s.newMember("idle", b)
+
+	s.ErrorIs(fail(a), errTest)
+	s.ErrorIs(fail(c), errTest)
+	s.Equal(2, b.Failures())
+	s.Equal(breaker.Closed, a.State())
+
+	s.ErrorIs(fail(a), errTest)
+
+	s.Equal(breaker.Open, a.State())
+	s.Equal(breaker.Open, c.State())
+	s.Equal(breaker.Open, idle.State(), "members without failures also open")
+	s.True(breaker.IsOpen(succeed(idle)))
+}
+
+func (s *BudgetSuite) TestSuccessResetsBudget() {
+	b := breaker.NewBudget(2)
+	a, c := s.newMember("a", b), s.newMember("c", b)
+
+	s.ErrorIs(fail(a), errTest)
+	s.NoError(succeed(c))
+	s.Zero(b.Failures())
+
+	s.ErrorIs(fail(a), errTest)
+
+	s.Equal(breaker.Closed, a.State())
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *BudgetSuite) TestMembersRecoverIndependently() {
+	b := breaker.NewBudget(1)
+	a, c := s.newMember("a", b), s.newMember("c", b)
+
+	s.ErrorIs(fail(a), errTest)
+	s.clock.Advance(breaker.DefaultOpenDuration)
+
+	s.Equal(breaker.HalfOpen, a.State())
+	s.Equal(breaker.HalfOpen, c.State())
+}
+
+func (s *BudgetSuite) TestExhaustedBudgetSkipsWarmingUpMembers() {
+	b := breaker.NewBudget(2)
+	a := s.newMember("a", b)
+	warming := breaker.New("warming",
+		breaker.WithFailureThreshold(100),
+		breaker.WithWarmup(time.Minute),
+		breaker.WithBudget(b),
+		breaker.WithClock(s.clock),
+	)
+
+	s.ErrorIs(fail(warming), errTest)
+	s.ErrorIs(fail(a), errTest)
+
+	s.Equal(breaker.Open, a.State())
+	s.Equal(breaker.Closed, warming.State())
+}
+
+func (s *BudgetSuite) TestLimitBelowOneIsOne() {
+	tests := map[string]int{
+		"zero":     0,
+		"negative": -3,
+	}
+
+	for name, limit := range tests {
+		s.Run(name, func() {
+			b := breaker.NewBudget(limit)
+			a, c := s.newMember("a", b), s.newMember("c", b)
+
+			s.ErrorIs(fail(a), errTest)
+
+			s.Zero(b.Failures())
+			s.Equal(breaker.Open, a.State())
+			s.Equal(breaker.Open, c.State())
+		})
+	}
+}
+
+func (s *BudgetSuite) TestRemovedMemberLeavesBudget() {
+	b := breaker.NewBudget(2)
+	a, removed := s.newMember("a", b), s.newMember("removed", b)
+
+	b.Remove(removed)
+	s.ErrorIs(fail(removed), errTest)
+	s.Zero(b.Failures(), "a removed member's failures do not count")
+
+	s.ErrorIs(fail(a), errTest)
+	s.ErrorIs(fail(a), errTest)
+
+	s.Equal(breaker.Open, a.State())
+	s.Equal(breaker.Closed, removed.State())
+}
+
+func (s *BudgetSuite) TestClosedMemberLeavesBudget() {
+	b := breaker.NewBudget(1)
+	a, closed := s.newMember("a", b), s.newMember("closed", b)
+
+	s.NoError(closed.Close())
+	s.ErrorIs(fail(a), errTest)
+
+	s.Equal(breaker.Open, a.State())
+	s.Equal(breaker.Closed, closed.State())
+}
+
+func (s *BudgetSuite) TestConcurrentMembers() {
+	b := breaker.NewBudget(50)
+	members := make([]*breaker.Circuit, 10)
+	for i := range members {
+		members[i] = breaker.New("m", breaker.WithFailureThreshold(1000), breaker.WithBudget(b))
+	}
+
+	var wg sync.WaitGroup
+	for _, m := range members {
+		wg.Go(func() {
+			for range 10 {
+				_ = fail(m)
+			}
+		})
+	}
+	wg.Wait()
+
+	for _, m := range members {
+		s.Equal(breaker.Open, m.State())
+	}
+}
@@ -0,0 +1,168 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// BatchPolicy determines when a DoBatch call counts as a failure.
+type BatchPolicy int
+
+const (
+	// BatchAnyFailure counts the batch as failed if any function fails.
+	BatchAnyFailure BatchPolicy = iota
+
+	// BatchMajorityFailure counts the batch as failed only if more than
+	// half of its functions fail.
+	BatchMajorityFailure
+)
+
+// BulkDo executes each of fns in order through the circuit and returns
+// their errors aligned with fns. Once a call is rejected because the
+// circuit is open, the remaining functions are skipped and their slots are
+// filled with that rejection. A function's own error never stops the
+// batch, even if it wraps ErrOpen.
+func (c *Circuit) BulkDo(ctx context.Context, fns []Func) []error {
+	errs := make([]error, len(fns))
+	for i, fn := range fns {
+		var ran bool
+		ran, errs[i] = c.doTracked(ctx, fn)
+		if !ran && IsOpen(errs[i]) {
+			for j := i + 1; j < len(fns); j++ {
+				errs[j] = errs[i]
+			}
+			break
+		}
+	}
+	return errs
+}
+
+// BulkDoConcurrent is like BulkDo but runs fns concurrently. Functions are
+// dispatched in order, each admitted by Do; once a call is rejected
+// because the circuit is open, functions not yet dispatched are skipped
+// and their slots are filled with that rejection, as in BulkDo. Functions
+// already running are not interrupted.
+func (c *Circuit) BulkDoConcurrent(ctx context.Context, fns []Func) []error {
+	errs := make([]error, len(fns))
+	var rejection atomic.Pointer[error]
+	var wg sync.WaitGroup
+	for i, fn := range fns {
+		if p := rejection.Load(); p != nil {
+			errs[i] = *p
+			continue
+		}
+		wg.Go(func() {
+			var ran bool
+			ran, errs[i] = c.doTracked(ctx, fn)
+			if !ran && IsOpen(errs[i]) {
+				err := errs[i]
+				rejection.CompareAndSwap(nil, &err)
+			}
+		})
+	}
+	wg.Wait()
+	return errs
+}
+
+// doTracked calls Do and reports whether fn ran, which tells a
+// rejection apart from an error of fn's that wraps ErrOpen.
+func (c *Circuit) doTracked(ctx context.Context, fn Func) (ran bool, err error) {
+	err = c.Do(ctx, func(ctx context.Context) error {
+		ran = true
+		return fn(ctx)
+	})
+	return ran, err
+}
+
+// DoBatch runs fns concurrently under a single admission decision and
+// records their combined outcome as one call, per the WithBatchPolicy
+// policy. It returns the errors aligned with fns. If the circuit rejects
+// the batch, none of fns run and every slot holds the rejection. The
+// failure condition is applied to each member once, and a failed batch is
+// recorded as a failure without consulting it again; hooks and observers
+// see an error wrapping the errors.Join of the failures. Under
+// WithRecoverPanics, a member that panics fails with a *PanicError, and
+// with repanic set DoBatch panics again once the batch is recorded.
+// Without it, a member's panic is carried to the goroutine that called
+// DoBatch and propagates from there, unrecorded, as a panic in fn passed
+// to Do would.
+func (c *Circuit) DoBatch(ctx context.Context, fns ...Func) []error {
+	errs := make([]error, len(fns))
+	if len(fns) == 0 {
+		return errs
+	}
+
+	ran := false
+	err := c.do(ctx, func(ctx context.Context) error {
+		ran = true
+		panics := make([]any, len(fns))
+		var wg sync.WaitGroup
+		for i, fn := range fns {
+			wg.Go(func() {
+				defer func() {
+					if r := recover(); r != nil {
+						panics[i] = r
+					}
+				}()
+				errs[i] = c.call(ctx, fn)
+			})
+		}
+		wg.Wait()
+		for _, r := range panics {
+			if r != nil {
+				panic(r)
+			}
+		}
+
+		var failures []error
+		for _, err := range errs {
+			if c.isFailure(ctx, err) {
+				failures = append(failures, err)
+			}
+		}
+		if c.cfg.batchPolicy == BatchMajorityFailure && len(failures)*2 <= len(fns) {
+			return nil
+		}
+		if len(failures) == 0 {
+			return nil
+		}
+		return batchFailure{errors.Join(failures...)}
+	}, isBatchFailure).Err
+	if !ran {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+	if c.cfg.repanic {
+		for _, err := range errs {
+			if pe, ok := err.(*PanicError); ok {
+				panic(pe.Value)
+			}
+		}
+	}
+	return errs
+}
+
+// batchFailure is the error DoBatch records for a failed batch. Its
+// members already passed the failure condition, so DoBatch classifies the
+// batch with isBatchFailure instead of asking again.
+type batchFailure struct {
+	error
+}
+
+func (e batchFailure) Unwrap() error {
+	return e.error
+}
+
+// isBatchFailure is DoBatch's classifier: a batch failed if its function
+// returned a batchFailure, or if the call was forced to fail.
+func isBatchFailure(_ context.Context, err error) bool {
+	if err == ErrForcedFailure {
+		return true
+	}
+	_, ok := err.(batchFailure)
+	return ok
+}
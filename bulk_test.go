@@ -0,0 +1,410 @@
+package breaker_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bjaus/breaker"
+	"github.com/stretchr/testify/suite"
+)
+
+type BulkSuite struct {
+	suite.Suite
+	clock *fakeClock
+}
+
+func TestBulkSuite(t *testing.T) {
+	suite.Run(t, new(BulkSuite))
+}
+
+func (s *BulkSuite) SetupTest() {
+	s.clock = newFakeClock()
+}
+
+func (s *BulkSuite) TestBulkDo_ReturnsAlignedErrors() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+
+	errs := c.BulkDo(context.Background(), []breaker.Func{
+		func(ctx context.Context) error { return nil },
+		func(ctx context.Context) error { return errTest },
+		func(ctx context.Context) error { return nil },
+	})
+
+	s.Require().Len(errs, 3)
+	s.NoError(errs[0])
+	s.ErrorIs(errs[1], errTest)
+	s.NoError(errs[2])
+}
+
+func (s *BulkSuite) TestBulkDo_ShortCircuitsWhenCircuitTrips() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(2),
+		breaker.WithClock(s.clock),
+	)
+
+	var called int
+	fail := func(ctx context.Context) error {
+		called++
+		return errTest
+	}
+
+	errs := c.BulkDo(context.Background(), []breaker.Func{fail, fail, fail, fail, fail})
+
+	s.Equal(2, called)
+	s.ErrorIs(errs[0], errTest)
+	s.ErrorIs(errs[1], errTest)
+	for _, err := range errs[2:] {
+		s.True(breaker.IsOpen(err))
+	}
+}
+
+func (s *BulkSuite) TestBulkDo_DownstreamOpenErrorDoesNotStopBatch() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(10),
+		breaker.WithClock(s.clock),
+	)
+	downstream := fmt.Errorf("downstream: %w", breaker.ErrOpen)
+
+	var called int
+	errs := c.BulkDo(context.Background(), []breaker.Func{
+		func(ctx context.Context) error { called++; return downstream },
+		func(ctx context.Context) error { called++; return nil },
+	})
+
+	s.Equal(2, called)
+	s.ErrorIs(errs[0], downstream)
+	s.NoError(errs[1])
+}
+
+func (s *BulkSuite) TestBulkDo_Empty() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+
+	s.Empty(c.BulkDo(context.Background(), nil))
+}
+
+func (s *BulkSuite) TestBulkDoConcurrent_RunsAll() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+
+	var called atomic.Int32
+	fns := make([]breaker.Func, 10)
+	for i := range fns {
+		fns[i] = func(ctx context.Context) error {
+			called.Add(1)
+			return nil
+		}
+	}
+
+	errs := c.BulkDoConcurrent(context.Background(), fns)
+
+	s.Len(errs, 10)
+	for _, err := range errs {
+		s.NoError(err)
+	}
+	s.Equal(int32(10), called.Load())
+}
+
+func (s *BulkSuite) TestBulkDoConcurrent_SkipsWhenOpen() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+
+	errs := c.BulkDoConcurrent(context.Background(), []breaker.Func{
+		func(ctx context.Context) error {
+			s.Fail("function should not be dispatched when circuit is open")
+			return nil
+		},
+		func(ctx context.Context) error {
+			s.Fail("function should not be dispatched when circuit is open")
+			return nil
+		},
+	})
+
+	for _, err := range errs {
+		s.True(breaker.IsOpen(err))
+	}
+}
+
+func (s *BulkSuite) TestBulkDoConcurrent_SkippedSlotsMatchBulkDo() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+	fns := []breaker.Func{
+		func(ctx context.Context) error { return nil },
+		func(ctx context.Context) error { return nil },
+	}
+
+	sequential := c.BulkDo(context.Background(), fns)
+	concurrent := c.BulkDoConcurrent(context.Background(), fns)
+
+	for i := range fns {
+		var want, got *breaker.OpenError
+		s.Require().ErrorAs(sequential[i], &want)
+		s.Require().ErrorAs(concurrent[i], &got)
+		s.ErrorIs(got.Cause, errTest)
+		s.Equal(want.OpenedAt, got.OpenedAt)
+	}
+}
+
+func (s *BulkSuite) TestBulkDoConcurrent_RunsWhenDoWouldAdmit() {
+	var enabled atomic.Bool
+	tests := map[string]struct {
+		circuit   func() *breaker.Circuit
+		afterTrip func()
+	}{
+		"dry run": {
+			circuit: func() *breaker.Circuit {
+				return breaker.New("test",
+					breaker.WithFailureThreshold(1),
+					breaker.WithDryRun(true),
+					breaker.WithClock(s.clock),
+				)
+			},
+			afterTrip: func() {},
+		},
+		"flag off": {
+			circuit: func() *breaker.Circuit {
+				enabled.Store(true)
+				return breaker.NewConditional("test", enabled.Load,
+					breaker.WithFailureThreshold(1),
+					breaker.WithClock(s.clock),
+				)
+			},
+			afterTrip: func() { enabled.Store(false) },
+		},
+	}
+
+	for name, tc := range tests {
+		s.Run(name, func() {
+			c := tc.circuit()
+			_ = c.Do(context.Background(), func(ctx context.Context) error {
+				return errTest
+			})
+			s.Require().Equal(breaker.Open, c.State())
+			tc.afterTrip()
+
+			var called atomic.Int32
+			errs := c.BulkDoConcurrent(context.Background(), []breaker.Func{
+				func(ctx context.Context) error { called.Add(1); return nil },
+				func(ctx context.Context) error { called.Add(1); return nil },
+			})
+
+			s.Equal(int32(2), called.Load())
+			for _, err := range errs {
+				s.NoError(err)
+			}
+		})
+	}
+}
+
+func (s *BulkSuite) TestBulkDoConcurrent_DownstreamOpenErrorDoesNotStopBatch() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(10),
+		breaker.WithClock(s.clock),
+	)
+	downstream := fmt.Errorf("downstream: %w", breaker.ErrOpen)
+
+	var called atomic.Int32
+	errs := c.BulkDoConcurrent(context.Background(), []breaker.Func{
+		func(ctx context.Context) error { called.Add(1); return downstream },
+		func(ctx context.Context) error { called.Add(1); return nil },
+	})
+
+	s.Equal(int32(2), called.Load())
+	s.ErrorIs(errs[0], downstream)
+	s.NoError(errs[1])
+}
+
+func (s *BulkSuite) TestDoBatch_RunsAllUnderOneAdmission() {
+	calls := 0
+	c := breaker.New("test",
+		breaker.WithClock(s.clock),
+		breaker.OnCall(func(name string, state breaker.State, err error) {
+			calls++
+		}),
+	)
+
+	var ran atomic.Int32
+	succeed := func(ctx context.Context) error {
+		ran.Add(1)
+		return nil
+	}
+	errs := c.DoBatch(context.Background(), succeed, succeed, succeed)
+
+	s.Equal([]error{nil, nil, nil}, errs)
+	s.Equal(int32(3), ran.Load())
+	s.Equal(1, calls)
+}
+
+func (s *BulkSuite) TestDoBatch_AnyFailureCountsOnce() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(2),
+		breaker.WithClock(s.clock),
+	)
+
+	errs := c.DoBatch(context.Background(),
+		func(ctx context.Context) error { return errTest },
+		func(ctx context.Context) error { return errTest },
+		func(ctx context.Context) error { return nil },
+	)
+
+	s.ErrorIs(errs[0], errTest)
+	s.ErrorIs(errs[1], errTest)
+	s.NoError(errs[2])
+	failures := c.Counts().Failures
+	s.Equal(1, failures)
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *BulkSuite) TestDoBatch_MajorityFailure() {
+	tests := map[string]struct {
+		failing int
+		want    int
+	}{
+		"minority fails": {failing: 1, want: 0},
+		"half fails":     {failing: 2, want: 0},
+		"majority fails": {failing: 3, want: 1},
+	}
+
+	for name, tc := range tests {
+		s.Run(name, func() {
+			c := breaker.New("test",
+				breaker.WithBatchPolicy(breaker.BatchMajorityFailure),
+				breaker.WithClock(s.clock),
+			)
+
+			fns := make([]breaker.Func, 4)
+			for i := range fns {
+				fns[i] = func(ctx context.Context) error {
+					if i < tc.failing {
+						return errTest
+					}
+					return nil
+				}
+			}
+			c.DoBatch(context.Background(), fns...)
+
+			failures := c.Counts().Failures
+			s.Equal(tc.want, failures)
+		})
+	}
+}
+
+func (s *BulkSuite) TestDoBatch_AppliesConditionOncePerMember() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.If(func(err error) bool { return err == errTest }),
+		breaker.WithClock(s.clock),
+	)
+
+	c.DoBatch(context.Background(),
+		func(ctx context.Context) error { return errTest },
+		func(ctx context.Context) error { return nil },
+	)
+
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BulkSuite) TestDo_ConditionAppliesToBatchFailureErrors() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.If(func(err error) bool { return false }),
+		breaker.WithClock(s.clock),
+	)
+
+	err := c.Do(context.Background(), func(ctx context.Context) error {
+		return breaker.NewBatchFailure(errTest)
+	})
+
+	s.ErrorIs(err, errTest)
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *BulkSuite) TestDoBatch_RecoversMemberPanics() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithRecoverPanics(false),
+		breaker.WithClock(s.clock),
+	)
+
+	errs := c.DoBatch(context.Background(),
+		func(ctx context.Context) error { panic("boom") },
+		func(ctx context.Context) error { return nil },
+	)
+
+	var pe *breaker.PanicError
+	s.Require().ErrorAs(errs[0], &pe)
+	s.Equal("boom", pe.Value)
+	s.NoError(errs[1])
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BulkSuite) TestDoBatch_RepanicsAfterRecording() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithRecoverPanics(true),
+		breaker.WithClock(s.clock),
+	)
+
+	s.PanicsWithValue("boom", func() {
+		c.DoBatch(context.Background(), func(ctx context.Context) error { panic("boom") })
+	})
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *BulkSuite) TestDoBatch_PropagatesMemberPanicToCaller() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithHalfOpenRequests(1),
+		breaker.WithClock(s.clock),
+	)
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+	s.clock.Advance(time.Minute)
+	s.Require().Equal(breaker.HalfOpen, c.State())
+
+	s.PanicsWithValue("boom", func() {
+		c.DoBatch(context.Background(),
+			func(ctx context.Context) error { panic("boom") },
+			func(ctx context.Context) error { return nil },
+		)
+	})
+
+	s.Equal(breaker.HalfOpen, c.State())
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}), "the panicking batch gave back its half-open slot")
+}
+
+func (s *BulkSuite) TestDoBatch_RejectsWholeBatchWhenOpen() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+
+	fail := func(ctx context.Context) error {
+		s.Fail("function should not be called when circuit is open")
+		return nil
+	}
+	errs := c.DoBatch(context.Background(), fail, fail)
+
+	s.Len(errs, 2)
+	for _, err := range errs {
+		s.True(breaker.IsOpen(err))
+	}
+}
@@ -0,0 +1,54 @@
+package breaker
+
+import "context"
+
+// Chain falls through an ordered list of circuits, such as one per region:
+// each call goes to the first circuit that admits it. Safe for concurrent
+// use.
+type Chain struct {
+	circuits []*Circuit
+}
+
+// NewChain creates a Chain that tries circuits in order.
+func NewChain(circuits ...*Circuit) *Chain {
+	return &Chain{circuits: append([]*Circuit(nil), circuits...)}
+}
+
+// Do executes fn through the first circuit that admits it. A circuit that
+// rejects the call because it is open passes it to the next one. Any other
+// outcome ends the chain: fn's result, including an application error, is
+// returned as is and the remaining circuits are not tried. If every circuit
+// is open, Do returns ErrOpen.
+func (c *Chain) Do(ctx context.Context, fn Func) error {
+	for _, m := range c.circuits {
+		ran := false
+		err := m.Do(ctx, func(ctx context.Context) error {
+			ran = true
+			return fn(ctx)
+		})
+		if ran || !IsOpen(err) {
+			return err
+		}
+	}
+	return ErrOpen
+}
+
+// State returns Closed if any member is closed, HalfOpen if any member is
+// half-open, and Open otherwise. A Chain with no members is always Open.
+func (c *Chain) State() State {
+	state := Open
+	for _, m := range c.circuits {
+		switch m.State() {
+		case Closed:
+			return Closed
+		case HalfOpen:
+			state = HalfOpen
+		}
+	}
+	return state
+}
+
+// Circuits returns the member circuits in order.
+func (c *Chain) Circuits() []*Circuit {
+	return append([]*Circuit(nil), c.circuits...)
+}
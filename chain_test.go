@@ -0,0 +1,133 @@
+package breaker_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bjaus/breaker"
+	"github.com/stretchr/testify/suite"
+)
+
+type ChainSuite struct {
+	suite.Suite
+	clock *fakeClock
+}
+
+func TestChainSuite(t *testing.T) {
+	suite.Run(t, new(ChainSuite))
+}
+
+func (s *ChainSuite) SetupTest() {
+	s.clock = newFakeClock()
+}
+
+func (s *ChainSuite) trip(c *breaker.Circuit) {
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+	s.Require().Equal(breaker.Open, c.State())
+}
+
+func (s *ChainSuite) newCircuit(name string) *breaker.Circuit {
+	return breaker.New(name,
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+}
+
+func (s *ChainSuite) TestDo_UsesPrimaryWhenClosed() {
+	primary, secondary := s.newCircuit("us-east"), s.newCircuit("us-west")
+	chain := breaker.NewChain(primary, secondary)
+
+	s.NoError(chain.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+
+	s.Equal(int64(1), primary.Counts().TotalCalls)
+	s.Zero(secondary.Counts().TotalCalls)
+}
+
+func (s *ChainSuite) TestDo_FallsThroughWhenPrimaryOpen() {
+	primary, secondary := s.newCircuit("us-east"), s.newCircuit("us-west")
+	chain := breaker.NewChain(primary, secondary)
+	s.trip(primary)
+
+	calls := 0
+	s.NoError(chain.Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		return nil
+	}))
+
+	s.Equal(1, calls)
+	s.Equal(int64(1), secondary.Counts().TotalCalls)
+}
+
+func (s *ChainSuite) TestDo_StopsOnApplicationError() {
+	primary, secondary := s.newCircuit("us-east"), s.newCircuit("us-west")
+	chain := breaker.NewChain(primary, secondary)
+
+	err := chain.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+
+	s.ErrorIs(err, errTest)
+	s.Zero(secondary.Counts().TotalCalls)
+}
+
+func (s *ChainSuite) TestDo_StopsOnOpenErrorFromFn() {
+	primary, secondary := s.newCircuit("us-east"), s.newCircuit("us-west")
+	chain := breaker.NewChain(primary, secondary)
+
+	err := chain.Do(context.Background(), func(ctx context.Context) error {
+		return breaker.ErrOpen
+	})
+
+	s.ErrorIs(err, breaker.ErrOpen)
+	s.Zero(secondary.Counts().TotalCalls, "expected an error from fn not to fall through even if it looks like a rejection")
+}
+
+func (s *ChainSuite) TestDo_AllOpenReturnsErrOpen() {
+	primary, secondary := s.newCircuit("us-east"), s.newCircuit("us-west")
+	chain := breaker.NewChain(primary, secondary)
+	s.trip(primary)
+	s.trip(secondary)
+
+	called := false
+	err := chain.Do(context.Background(), func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+
+	s.ErrorIs(err, breaker.ErrOpen)
+	s.False(called)
+}
+
+func (s *ChainSuite) TestState() {
+	primary, secondary := s.newCircuit("us-east"), s.newCircuit("us-west")
+	chain := breaker.NewChain(primary, secondary)
+	s.Equal(breaker.Closed, chain.State())
+
+	s.trip(primary)
+	s.Equal(breaker.Closed, chain.State())
+
+	s.trip(secondary)
+	s.Equal(breaker.Open, chain.State())
+
+	s.clock.Advance(breaker.DefaultOpenDuration)
+	s.Equal(breaker.HalfOpen, chain.State())
+
+	s.Equal(breaker.Open, breaker.NewChain().State())
+}
+
+func (s *ChainSuite) TestRun_AcceptsChain() {
+	primary, secondary := s.newCircuit("us-east"), s.newCircuit("us-west")
+	chain := breaker.NewChain(primary, secondary)
+	s.trip(primary)
+
+	got, err := breaker.Run(context.Background(), chain, func(ctx context.Context) (string, error) {
+		return "us-west", nil
+	})
+
+	s.NoError(err)
+	s.Equal("us-west", got)
+}
@@ -1,14 +1,102 @@
 package breaker
 
-import "time"
+import (
+	"sync"
+	"time"
+)
 
-// Clock abstracts time for testing.
+// Clock abstracts time for testing. A Clock that only implements Now
+// controls what time the circuit sees, while its timers run in real time;
+// implement TimerClock to control those too.
 type Clock interface {
 	Now() time.Time
 }
 
-type realClock struct{}
+// TimerClock is a Clock that also schedules the circuit's timers, such as
+// WithAutoReset and the open-duration wake-up in WaitForState, so a fake
+// clock controls them as well as Now.
+type TimerClock interface {
+	Clock
+
+	// AfterFunc calls f in its own goroutine after d has elapsed and
+	// returns a function that cancels the call if it has not started.
+	AfterFunc(d time.Duration, f func()) (stop func())
+}
+
+// RealTimers implements AfterFunc with real timers. Embed it in a Clock
+// that only controls Now to make it a TimerClock explicitly; a Clock
+// without AfterFunc gets the same behavior.
+type RealTimers struct{}
+
+// AfterFunc calls f after d has elapsed, using time.AfterFunc.
+func (RealTimers) AfterFunc(d time.Duration, f func()) func() {
+	t := time.AfterFunc(d, f)
+	return func() { t.Stop() }
+}
+
+// afterFunc schedules f on clock if it is a TimerClock, and on a real
+// timer otherwise.
+func afterFunc(clock Clock, d time.Duration, f func()) func() {
+	if tc, ok := clock.(TimerClock); ok {
+		return tc.AfterFunc(d, f)
+	}
+	return RealTimers{}.AfterFunc(d, f)
+}
+
+var (
+	defaultClockMu sync.RWMutex
+	defaultClock   Clock = realClock{}
+)
+
+// SetDefaultClock sets the clock used by circuits created without
+// WithClock. Passing nil restores the real clock. It is meant for tests,
+// which can set a fake clock once in TestMain instead of passing WithClock
+// everywhere; production code should not call it. Circuits keep the clock
+// they were created with.
+func SetDefaultClock(clock Clock) {
+	if clock == nil {
+		clock = realClock{}
+	}
+	defaultClockMu.Lock()
+	defer defaultClockMu.Unlock()
+	defaultClock = clock
+}
+
+// DefaultClock returns the clock used by circuits created without
+// WithClock.
+func DefaultClock() Clock {
+	defaultClockMu.RLock()
+	defer defaultClockMu.RUnlock()
+	return defaultClock
+}
+
+// after is the clock's equivalent of time.After, built on AfterFunc so
+// fake clocks fire it when advanced. The returned stop function releases
+// the timer early.
+func after(clock Clock, d time.Duration) (<-chan time.Time, func()) {
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- clock.Now()
+		return ch, func() {}
+	}
+	stop := afterFunc(clock, d, func() {
+		ch <- clock.Now()
+	})
+	return ch, stop
+}
+
+type realClock struct {
+	RealTimers
+}
 
 func (realClock) Now() time.Time {
 	return time.Now()
 }
+
+// funcClock adapts a now function to Clock for WithClockFunc. Having no
+// AfterFunc, its timers run in real time.
+type funcClock func() time.Time
+
+func (f funcClock) Now() time.Time {
+	return f()
+}
@@ -0,0 +1,79 @@
+package breaker
+
+import (
+	"context"
+	"slices"
+)
+
+// CompositeMode determines how a Composite aggregates member states.
+type CompositeMode int
+
+const (
+	// CompositeAny reports Open when any member is open.
+	CompositeAny CompositeMode = iota
+
+	// CompositeAll reports Open only when every member is open.
+	CompositeAll
+)
+
+// Composite represents a logical operation that depends on several circuits.
+// It aggregates member state for reads; it does not record outcomes itself,
+// so fn should still call through the member circuits. Safe for concurrent use.
+type Composite struct {
+	mode     CompositeMode
+	circuits []*Circuit
+}
+
+// NewComposite creates a Composite over circuits using mode.
+func NewComposite(mode CompositeMode, circuits ...*Circuit) *Composite {
+	return &Composite{
+		mode:     mode,
+		circuits: slices.Clone(circuits),
+	}
+}
+
+// State returns Open when the members are open according to the mode,
+// HalfOpen when any member is half-open, and Closed otherwise.
+// A Composite with no members is always Closed.
+func (c *Composite) State() State {
+	return aggregateState(c.circuits, c.mode == CompositeAll)
+}
+
+// aggregateState combines the members' states. With all set, the result
+// is Open only if every member is open; otherwise any open member makes it
+// Open.
+func aggregateState(circuits []*Circuit, all bool) State {
+	open, halfOpen := 0, 0
+	for _, m := range circuits {
+		switch m.State() {
+		case Open:
+			open++
+		case HalfOpen:
+			halfOpen++
+		}
+	}
+
+	switch {
+	case !all && open > 0:
+		return Open
+	case all && open > 0 && open == len(circuits):
+		return Open
+	case halfOpen > 0:
+		return HalfOpen
+	default:
+		return Closed
+	}
+}
+
+// Do executes fn if the composite is not open, and returns ErrOpen otherwise.
+func (c *Composite) Do(ctx context.Context, fn Func) error {
+	if c.State() == Open {
+		return ErrOpen
+	}
+	return fn(ctx)
+}
+
+// Circuits returns the member circuits.
+func (c *Composite) Circuits() []*Circuit {
+	return append([]*Circuit(nil), c.circuits...)
+}
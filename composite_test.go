@@ -0,0 +1,109 @@
+package breaker_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bjaus/breaker"
+	"github.com/stretchr/testify/suite"
+)
+
+type CompositeSuite struct {
+	suite.Suite
+	clock *fakeClock
+}
+
+func TestCompositeSuite(t *testing.T) {
+	suite.Run(t, new(CompositeSuite))
+}
+
+func (s *CompositeSuite) SetupTest() {
+	s.clock = newFakeClock()
+}
+
+func (s *CompositeSuite) trip(c *breaker.Circuit) {
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+	s.Require().Equal(breaker.Open, c.State())
+}
+
+func (s *CompositeSuite) newCircuit(name string) *breaker.Circuit {
+	return breaker.New(name,
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+}
+
+func (s *CompositeSuite) TestState_AnyOpensWhenOneMemberOpens() {
+	a, b := s.newCircuit("a"), s.newCircuit("b")
+	comp := breaker.NewComposite(breaker.CompositeAny, a, b)
+
+	s.Equal(breaker.Closed, comp.State())
+
+	s.trip(a)
+
+	s.Equal(breaker.Open, comp.State())
+}
+
+func (s *CompositeSuite) TestNewComposite_CopiesMembers() {
+	a, b := s.newCircuit("a"), s.newCircuit("b")
+	members := []*breaker.Circuit{a}
+	comp := breaker.NewComposite(breaker.CompositeAny, members...)
+
+	members[0] = b
+	s.trip(b)
+
+	s.Equal(breaker.Closed, comp.State())
+}
+
+func (s *CompositeSuite) TestState_AllOpensOnlyWhenEveryMemberOpens() {
+	a, b := s.newCircuit("a"), s.newCircuit("b")
+	comp := breaker.NewComposite(breaker.CompositeAll, a, b)
+
+	s.trip(a)
+	s.Equal(breaker.Closed, comp.State())
+
+	s.trip(b)
+	s.Equal(breaker.Open, comp.State())
+}
+
+func (s *CompositeSuite) TestState_HalfOpenWhenMemberHalfOpen() {
+	a, b := s.newCircuit("a"), s.newCircuit("b")
+	comp := breaker.NewComposite(breaker.CompositeAll, a, b)
+
+	s.trip(a)
+	s.clock.Advance(breaker.DefaultOpenDuration)
+
+	s.Equal(breaker.HalfOpen, comp.State())
+}
+
+func (s *CompositeSuite) TestState_EmptyIsClosed() {
+	s.Equal(breaker.Closed, breaker.NewComposite(breaker.CompositeAll).State())
+}
+
+func (s *CompositeSuite) TestDo_RejectsWhenOpen() {
+	a := s.newCircuit("a")
+	comp := breaker.NewComposite(breaker.CompositeAny, a)
+
+	s.trip(a)
+
+	err := comp.Do(context.Background(), func(ctx context.Context) error {
+		s.Fail("function should not be called when composite is open")
+		return nil
+	})
+	s.True(breaker.IsOpen(err))
+}
+
+func (s *CompositeSuite) TestDo_ExecutesWhenAvailable() {
+	a, b := s.newCircuit("a"), s.newCircuit("b")
+	comp := breaker.NewComposite(breaker.CompositeAny, a, b)
+
+	called := false
+	s.NoError(comp.Do(context.Background(), func(ctx context.Context) error {
+		called = true
+		return nil
+	}))
+	s.True(called)
+	s.Len(comp.Circuits(), 2)
+}
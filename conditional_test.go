@@ -0,0 +1,176 @@
+package breaker_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bjaus/breaker"
+	"github.com/stretchr/testify/suite"
+)
+
+type ConditionalSuite struct {
+	suite.Suite
+	clock *fakeClock
+}
+
+func TestConditionalSuite(t *testing.T) {
+	suite.Run(t, new(ConditionalSuite))
+}
+
+func (s *ConditionalSuite) SetupTest() {
+	s.clock = newFakeClock()
+}
+
+func (s *ConditionalSuite) TestNewConditional_PassesThroughWhileDisabled() {
+	var enabled atomic.Bool
+	var calls int
+	c := breaker.NewConditional("test", enabled.Load,
+		breaker.WithFailureThreshold(1),
+		breaker.OnCall(func(name string, state breaker.State, err error) {
+			calls++
+		}),
+		breaker.WithClock(s.clock),
+	)
+
+	for range 3 {
+		s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		}), errTest)
+	}
+
+	s.Equal(breaker.Closed, c.State())
+	s.Zero(calls)
+	s.Equal(breaker.Stats{}, c.Counts())
+}
+
+func (s *ConditionalSuite) TestNewConditional_BreaksWhileEnabled() {
+	var enabled atomic.Bool
+	c := breaker.NewConditional("test", enabled.Load,
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+
+	enabled.Store(true)
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.True(breaker.IsOpen(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	})))
+
+	enabled.Store(false)
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}), "expected the flag to bypass the open circuit")
+
+	enabled.Store(true)
+	s.True(breaker.IsOpen(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	})), "expected the circuit to resume where it was")
+}
+
+func (s *ConditionalSuite) TestNewConditional_GatesAllow() {
+	c := breaker.NewConditional("test", func() bool { return false },
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+
+	tok, err := c.Allow()
+	s.Require().NoError(err)
+	tok.Fail(errTest)
+
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *ConditionalSuite) TestNewConditional_DrainTracksCallsWhileDisabled() {
+	c := breaker.NewConditional("test", func() bool { return false },
+		breaker.WithClock(s.clock),
+	)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+	s.Equal(1, c.InFlight())
+
+	drained := make(chan error, 1)
+	go func() {
+		drained <- c.Drain(context.Background())
+	}()
+	s.Eventually(func() bool {
+		return c.Do(context.Background(), func(ctx context.Context) error {
+			return nil
+		}) == breaker.ErrDraining
+	}, time.Second, time.Millisecond)
+
+	select {
+	case <-drained:
+		s.Fail("Drain returned while a call was running")
+	default:
+	}
+	close(release)
+	s.NoError(<-drained)
+}
+
+func (s *ConditionalSuite) TestNewConditional_RecoversPanicsWhileDisabled() {
+	c := breaker.NewConditional("test", func() bool { return false },
+		breaker.WithRecoverPanics(false),
+		breaker.WithClock(s.clock),
+	)
+
+	err := c.Do(context.Background(), func(ctx context.Context) error {
+		panic("boom")
+	})
+
+	var pe *breaker.PanicError
+	s.Require().ErrorAs(err, &pe)
+	s.Equal("boom", pe.Value)
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *ConditionalSuite) TestNewConditional_NilEnabledIsAlwaysOn() {
+	c := breaker.NewConditional("test", nil,
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *ConditionalSuite) TestNewConditional_ToggleDuringCallsIsRaceFree() {
+	var enabled atomic.Bool
+	c := breaker.NewConditional("test", enabled.Load,
+		breaker.WithFailureThreshold(1000),
+	)
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for range 200 {
+				_ = c.Do(context.Background(), func(ctx context.Context) error {
+					return errTest
+				})
+			}
+		})
+	}
+	wg.Go(func() {
+		for i := range 200 {
+			enabled.Store(i%2 == 0)
+		}
+	})
+	wg.Wait()
+
+	s.Equal(breaker.Closed, c.State())
+}
@@ -0,0 +1,91 @@
+package breaker
+
+import "time"
+
+// Config is a serializable form of the core circuit options, for circuits
+// configured from JSON or YAML files. Each field corresponds to an option;
+// a zero field leaves that option at its default. Hooks, the clock, and
+// other options that are not plain data have no field: pass them alongside
+// Options.
+type Config struct {
+	// Name is the circuit name.
+	Name string `json:"name"`
+
+	// Namespace corresponds to WithNamespace.
+	Namespace string `json:"namespace,omitempty"`
+
+	// FailureThreshold corresponds to WithFailureThreshold.
+	FailureThreshold int `json:"failure_threshold,omitempty"`
+
+	// SuccessThreshold corresponds to WithSuccessThreshold.
+	SuccessThreshold int `json:"success_threshold,omitempty"`
+
+	// OpenDurationMs corresponds to WithOpenDuration, in milliseconds.
+	OpenDurationMs int64 `json:"open_duration_ms,omitempty"`
+
+	// HalfOpenRequests corresponds to WithHalfOpenRequests.
+	HalfOpenRequests int `json:"half_open_requests,omitempty"`
+}
+
+// Validate reports the first invalid field as a *ConfigError whose Field
+// is the Config field name. Zero fields are valid and mean the default.
+func (c Config) Validate() error {
+	switch {
+	case c.FailureThreshold < 0:
+		return &ConfigError{Field: "FailureThreshold", Message: "must not be negative"}
+	case c.SuccessThreshold < 0:
+		return &ConfigError{Field: "SuccessThreshold", Message: "must not be negative"}
+	case c.OpenDurationMs < 0:
+		return &ConfigError{Field: "OpenDurationMs", Message: "must not be negative"}
+	case c.HalfOpenRequests < 0:
+		return &ConfigError{Field: "HalfOpenRequests", Message: "must not be negative"}
+	}
+	return nil
+}
+
+// Options returns the options equivalent to c, for combining with options
+// that Config cannot express:
+//
+//	opts := append(cfg.Options(), breaker.OnStateChange(logChange))
+//	circuit, err := breaker.NewWithError(cfg.Name, opts...)
+func (c Config) Options() []Option {
+	var opts []Option
+	if c.Namespace != "" {
+		opts = append(opts, WithNamespace(c.Namespace))
+	}
+	if c.FailureThreshold != 0 {
+		opts = append(opts, WithFailureThreshold(c.FailureThreshold))
+	}
+	if c.SuccessThreshold != 0 {
+		opts = append(opts, WithSuccessThreshold(c.SuccessThreshold))
+	}
+	if c.OpenDurationMs != 0 {
+		opts = append(opts, WithOpenDuration(time.Duration(c.OpenDurationMs)*time.Millisecond))
+	}
+	if c.HalfOpenRequests != 0 {
+		opts = append(opts, WithHalfOpenRequests(c.HalfOpenRequests))
+	}
+	return opts
+}
+
+// NewFromConfig creates a circuit from cfg. It returns the error from
+// Validate if cfg is invalid.
+func NewFromConfig(cfg Config) (*Circuit, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return NewWithError(cfg.Name, cfg.Options()...)
+}
+
+// Config returns the circuit's configuration in the form NewFromConfig
+// accepts, with defaults filled in.
+func (c *Circuit) Config() Config {
+	return Config{
+		Name:             c.name,
+		Namespace:        c.cfg.namespace,
+		FailureThreshold: c.cfg.failureThreshold,
+		SuccessThreshold: c.cfg.successThreshold,
+		OpenDurationMs:   c.cfg.openDuration.Milliseconds(),
+		HalfOpenRequests: c.cfg.halfOpenRequests,
+	}
+}
@@ -0,0 +1,122 @@
+package breaker_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bjaus/breaker"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type ConfigSuite struct {
+	suite.Suite
+}
+
+func TestConfigSuite(t *testing.T) {
+	suite.Run(t, new(ConfigSuite))
+}
+
+func (s *ConfigSuite) TestNewFromConfig_AppliesFields() {
+	var cfg breaker.Config
+	s.Require().NoError(json.Unmarshal([]byte(`{
+		"name": "payments",
+		"failure_threshold": 1,
+		"success_threshold": 3,
+		"open_duration_ms": 1500,
+		"half_open_requests": 4
+	}`), &cfg))
+
+	c, err := breaker.NewFromConfig(cfg)
+	s.Require().NoError(err)
+
+	s.Equal("payments", c.Name())
+	s.Equal(cfg, c.Config())
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *ConfigSuite) TestNewFromConfig_ZeroFieldsUseDefaults() {
+	c, err := breaker.NewFromConfig(breaker.Config{Name: "api"})
+	s.Require().NoError(err)
+
+	s.Equal(breaker.Config{
+		Name:             "api",
+		FailureThreshold: breaker.DefaultFailureThreshold,
+		SuccessThreshold: breaker.DefaultSuccessThreshold,
+		OpenDurationMs:   breaker.DefaultOpenDuration.Milliseconds(),
+		HalfOpenRequests: breaker.DefaultHalfOpenRequests,
+	}, c.Config())
+}
+
+func (s *ConfigSuite) TestNewFromConfig_RejectsInvalidConfig() {
+	c, err := breaker.NewFromConfig(breaker.Config{Name: "api", OpenDurationMs: -1})
+
+	s.Nil(c)
+	var cfgErr *breaker.ConfigError
+	s.Require().ErrorAs(err, &cfgErr)
+	s.Equal("OpenDurationMs", cfgErr.Field)
+}
+
+func (s *ConfigSuite) TestOptions_CombineWithOtherOptions() {
+	cfg := breaker.Config{Name: "api", FailureThreshold: 2}
+	var changes int
+	opts := append(cfg.Options(), breaker.OnStateChange(func(name string, from, to breaker.State) {
+		changes++
+	}))
+
+	c, err := breaker.NewWithError(cfg.Name, opts...)
+	s.Require().NoError(err)
+
+	for range 2 {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		})
+	}
+	s.Equal(1, changes)
+}
+
+func (s *ConfigSuite) TestConfig_ReflectsOptions() {
+	c := breaker.New("api",
+		breaker.WithFailureThreshold(7),
+		breaker.WithOpenDuration(2*time.Second),
+	)
+
+	cfg := c.Config()
+	s.Equal(7, cfg.FailureThreshold)
+	s.Equal(int64(2000), cfg.OpenDurationMs)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := map[string]struct {
+		cfg   breaker.Config
+		field string
+	}{
+		"zero value":                 {cfg: breaker.Config{}},
+		"negative failure threshold": {cfg: breaker.Config{FailureThreshold: -1}, field: "FailureThreshold"},
+		"negative success threshold": {cfg: breaker.Config{SuccessThreshold: -1}, field: "SuccessThreshold"},
+		"negative open duration":     {cfg: breaker.Config{OpenDurationMs: -1}, field: "OpenDurationMs"},
+		"negative half-open requests": {
+			cfg:   breaker.Config{HalfOpenRequests: -1},
+			field: "HalfOpenRequests",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if tc.field == "" {
+				require.NoError(t, err)
+				return
+			}
+			var cfgErr *breaker.ConfigError
+			require.ErrorAs(t, err, &cfgErr)
+			require.Equal(t, tc.field, cfgErr.Field)
+		})
+	}
+}
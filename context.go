@@ -0,0 +1,91 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrNoCircuit is returned by DoFromContext when ctx carries no circuit.
+var ErrNoCircuit = errors.New("no circuit in context")
+
+type circuitKey struct{}
+
+// reentrancyKey marks a context passed to fn by c's Do, for
+// WithReentrancyCheck.
+type reentrancyKey struct{ c *Circuit }
+
+func enter(ctx context.Context, c *Circuit) context.Context {
+	return context.WithValue(ctx, reentrancyKey{c}, struct{}{})
+}
+
+func entered(ctx context.Context, c *Circuit) bool {
+	return ctx.Value(reentrancyKey{c}) != nil
+}
+
+type forceKey struct{}
+
+// forceMode is the set of overrides carried by a context from WithForceAllow
+// and WithForceFail.
+type forceMode uint8
+
+const (
+	forceAllow forceMode = 1 << iota
+	forceFail
+)
+
+// forcing is set once either override has been requested, so that Do only
+// looks them up in the context after that.
+var forcing atomic.Bool
+
+// WithForceAllow returns a copy of ctx that makes Do run its function even
+// when the circuit would reject the call. A call admitted only because of
+// the override is not recorded, like a call on a disabled circuit. It is
+// meant for tests and fault injection.
+func WithForceAllow(ctx context.Context) context.Context {
+	return withForce(ctx, forceAllow)
+}
+
+// WithForceFail returns a copy of ctx that makes Do record a failure
+// without running its function. The call is admitted as usual, and if it
+// is, Do returns ErrForcedFailure, which counts as a failure whatever the
+// circuit's condition. Combined with WithForceAllow, a call the circuit
+// would reject returns ErrForcedFailure without being recorded. It is meant
+// for tests and fault injection.
+func WithForceFail(ctx context.Context) context.Context {
+	return withForce(ctx, forceFail)
+}
+
+func withForce(ctx context.Context, mode forceMode) context.Context {
+	forcing.Store(true)
+	return context.WithValue(ctx, forceKey{}, forced(ctx)|mode)
+}
+
+func forced(ctx context.Context) forceMode {
+	if !forcing.Load() {
+		return 0
+	}
+	mode, _ := ctx.Value(forceKey{}).(forceMode)
+	return mode
+}
+
+// InjectCircuit returns a copy of ctx that carries c.
+func InjectCircuit(ctx context.Context, c *Circuit) context.Context {
+	return context.WithValue(ctx, circuitKey{}, c)
+}
+
+// CircuitFromContext returns the circuit carried by ctx, if any.
+func CircuitFromContext(ctx context.Context) (*Circuit, bool) {
+	c, ok := ctx.Value(circuitKey{}).(*Circuit)
+	return c, ok && c != nil
+}
+
+// DoFromContext executes fn with the circuit carried by ctx. It returns
+// ErrNoCircuit without calling fn if ctx carries no circuit.
+func DoFromContext(ctx context.Context, fn Func) error {
+	c, ok := CircuitFromContext(ctx)
+	if !ok {
+		return ErrNoCircuit
+	}
+	return c.Do(ctx, fn)
+}
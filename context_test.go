@@ -0,0 +1,71 @@
+package breaker_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bjaus/breaker"
+	"github.com/stretchr/testify/suite"
+)
+
+type ContextSuite struct {
+	suite.Suite
+	clock *fakeClock
+}
+
+func TestContextSuite(t *testing.T) {
+	suite.Run(t, new(ContextSuite))
+}
+
+func (s *ContextSuite) SetupTest() {
+	s.clock = newFakeClock()
+}
+
+func (s *ContextSuite) TestCircuitFromContext_ReturnsInjectedCircuit() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+
+	got, ok := breaker.CircuitFromContext(breaker.InjectCircuit(context.Background(), c))
+
+	s.True(ok)
+	s.Same(c, got)
+}
+
+func (s *ContextSuite) TestCircuitFromContext_ReportsMissingCircuit() {
+	got, ok := breaker.CircuitFromContext(context.Background())
+
+	s.False(ok)
+	s.Nil(got)
+}
+
+func (s *ContextSuite) TestCircuitFromContext_IgnoresNilCircuit() {
+	_, ok := breaker.CircuitFromContext(breaker.InjectCircuit(context.Background(), nil))
+
+	s.False(ok)
+}
+
+func (s *ContextSuite) TestDoFromContext_ReturnsErrNoCircuit() {
+	err := breaker.DoFromContext(context.Background(), func(ctx context.Context) error {
+		s.Fail("function should not be called without a circuit")
+		return nil
+	})
+
+	s.ErrorIs(err, breaker.ErrNoCircuit)
+}
+
+func (s *ContextSuite) TestDoFromContext_RespectsCircuitState() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+	ctx := breaker.InjectCircuit(context.Background(), c)
+
+	s.ErrorIs(breaker.DoFromContext(ctx, func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+	s.Equal(breaker.Open, c.State())
+
+	s.True(breaker.IsOpen(breaker.DoFromContext(ctx, func(ctx context.Context) error {
+		s.Fail("function should not be called when circuit is open")
+		return nil
+	})))
+}
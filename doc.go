@@ -6,7 +6,7 @@
 //   - Fast Rejection: Open circuits reject calls immediately without load
 //   - Gradual Recovery: Half-open state tests if the service has recovered
 //   - Lifecycle Hooks: OnStateChange, OnCall, OnReject for observability
-//   - Zero Dependencies: Only the Go standard library
+//   - Zero Dependencies: The core package uses only the Go standard library
 //
 // # Quick Start
 //
@@ -56,6 +56,58 @@
 //	    breaker.WithHalfOpenRequests(3),      // Allow 3 requests in half-open
 //	)
 //
+// For downstreams with a hard quota, WithCallLimit opens the circuit after a
+// number of calls in each closed period, regardless of their outcome:
+//
+//	breaker.WithCallLimit(1000)
+//
+// Consecutive failures can go stale on quiet circuits. WithWindow starts the
+// count over when the previous failure is older than the window:
+//
+//	breaker.WithWindow(10*time.Minute)
+//
+// Successes in between reset the consecutive count, so a burst of failures
+// mixed with successes may never trip it. WithVelocityThreshold also opens
+// the circuit when a number of failures land within a rolling window:
+//
+//	breaker.WithVelocityThreshold(10, time.Second)
+//
+// WithFailureThresholdFunc makes the threshold dynamic. The func is
+// consulted on each failure, so an autotuning loop can adjust it live:
+//
+//	var threshold atomic.Int64 // set by the tuning loop
+//	breaker.WithFailureThresholdFunc(func() int { return int(threshold.Load()) })
+//
+// To restore a persisted circuit on startup, WithInitialState starts it in
+// the saved state; an open circuit keeps its original open timestamp:
+//
+//	breaker.WithInitialState(breaker.Open, savedOpenedAt)
+//
+// MarshalState and RestoreState save and restore the full runtime state,
+// including counts. Create the restored circuit with the same options:
+//
+//	data, err := circuit.MarshalState()
+//	// ... after restart ...
+//	err = circuit.RestoreState(data)
+//
+// New never fails: invalid settings, such as a zero threshold, fall back to
+// their defaults. Use NewWithError to reject them with a *ConfigError, or
+// MustNew to panic with one:
+//
+//	circuit, err := breaker.NewWithError("payment", opts...)
+//
+//	var paymentCircuit = breaker.MustNew("payment", breaker.WithFailureThreshold(5))
+//
+// For configuration files, Config holds the core settings as plain data.
+// NewFromConfig validates it and creates the circuit, and Circuit.Config
+// returns it back with defaults filled in:
+//
+//	var cfg breaker.Config // {"name": "payment", "failure_threshold": 5, "open_duration_ms": 10000}
+//	if err := json.Unmarshal(data, &cfg); err != nil {
+//	    return err
+//	}
+//	circuit, err := breaker.NewFromConfig(cfg)
+//
 // Default values:
 //
 //   - FailureThreshold: 5 consecutive failures
@@ -65,7 +117,10 @@
 //
 // # Failure Conditions
 //
-// By default, any non-nil error counts as a failure. Customize this with If:
+// By default, any non-nil error counts as a failure, except a
+// context.Canceled error caused by the caller cancelling the context passed
+// to Do; a client giving up is not the downstream's fault. Use
+// WithCountCanceled(true) to count those too. Customize this with If:
 //
 //	// Only count specific errors as failures
 //	circuit := breaker.New("api",
@@ -83,11 +138,65 @@
 //	    }),
 //	)
 //
+// Use WithContextCondition when the decision depends on the call's context,
+// such as ignoring errors caused by the caller cancelling. It takes
+// precedence over If and IfNot:
+//
+//	circuit := breaker.New("api",
+//	    breaker.WithContextCondition(func(ctx context.Context, err error) bool {
+//	        return err != nil && ctx.Err() == nil
+//	    }),
+//	)
+//
 // Use Not to invert any condition:
 //
 //	isTransient := func(err error) bool { return errors.Is(err, ErrTimeout) }
 //	isPermanent := breaker.Not(isTransient)
 //
+// IsErr and IsType build conditions from errors.Is and errors.As, and AnyOf
+// and AllOf combine them:
+//
+//	breaker.If(breaker.AnyOf(
+//	    breaker.IsErr(ErrTimeout),
+//	    breaker.IsType[*net.OpError](),
+//	))
+//
+// Failures count 1 each by default. WithFailureWeight grades them, so
+// severe errors trip the circuit sooner; a weight of 0 ignores the error:
+//
+//	breaker.WithFailureWeight(func(err error) int {
+//	    if errors.Is(err, ErrUnavailable) {
+//	        return 3
+//	    }
+//	    return 1
+//	})
+//
+// Some errors mean "stop everything now". WithTripImmediatelyOn opens the
+// circuit on the first matching error, whatever the count:
+//
+//	breaker.WithTripImmediatelyOn(func(err error) bool {
+//	    return errors.Is(err, ErrCredentialsRevoked)
+//	})
+//
+// Panics in fn propagate without being recorded. WithRecoverPanics records
+// them as failures with a *PanicError, then either returns that error or,
+// with repanic set, panics again:
+//
+//	breaker.WithRecoverPanics(false) // return *PanicError from Do
+//
+// # Warm-up
+//
+// Cold caches and connection pools can cause a burst of errors right after
+// startup. WithWarmup gives a new circuit a probation period during which
+// failures are counted but cannot open it:
+//
+//	circuit := breaker.New("api",
+//	    breaker.WithWarmup(time.Minute),
+//	)
+//
+// WithWarmupCalls instead ignores the outcome of the first N closed-state
+// calls entirely.
+//
 // # Lifecycle Hooks
 //
 // Hooks provide observability without coupling to a specific logger or metrics system:
@@ -99,7 +208,7 @@
 //	            "from", from,
 //	            "to", to,
 //	        )
-//	        metrics.Gauge("circuit.state", float64(to), "circuit:"+name)
+//	        metrics.Gauge("circuit.state", float64(to.Code()), "circuit:"+name)
 //	    }),
 //	    breaker.OnCall(func(name string, state breaker.State, err error) {
 //	        if err != nil {
@@ -113,11 +222,112 @@
 //	    }),
 //	)
 //
+// State.Code returns a stable numeric encoding (0=closed, 1=half-open,
+// 2=open) intended for gauges; use it rather than converting State directly.
+//
 // Available hooks:
 //
 //   - OnStateChange: Called when circuit transitions between states
 //   - OnCall: Called after each call attempt (success or failure)
+//   - OnCallTagged: Like OnCall, but also receives the circuit's tags
 //   - OnReject: Called when a call is rejected due to open circuit
+//   - OnRejectReason: Like OnReject, but also receives why: ReasonOpen,
+//     ReasonHalfOpenBudget, or ReasonOverloaded
+//   - OnRecover: Called when trial calls close the circuit, with the total
+//     downtime; unlike OnStateChange, Reset does not trigger it
+//   - OnProbe: Called with the outcome of each half-open trial, for
+//     tracking how reliably a dependency recovers
+//
+// On a very busy circuit, WithSamplingRate reports only a fraction of calls
+// to OnCall and EventCall observers. State changes and rejections are never
+// sampled:
+//
+//	breaker.WithSamplingRate(0.01)
+//
+// Hooks accumulate, so passing OnCall twice calls both. To package several
+// hooks into a reusable integration, implement Observer and register it
+// with WithObserver; combine observers with MultiObserver. Observers receive
+// a single CircuitEvent type whose Kind identifies the call, state change,
+// or reject. The hook options are thin wrappers around observers, so the
+// two interoperate freely:
+//
+//	breaker.WithObserver(breaker.ObserverFunc(func(e breaker.CircuitEvent) {
+//	    if e.Kind == breaker.EventCall {
+//	        metrics.Histogram("circuit.latency", e.Duration, "circuit:"+e.Name)
+//	    }
+//	}))
+//
+// To handle events off the call path, WithEventBuffer queues them on a
+// channel for one consumer to drain. Publishing never blocks; a full
+// buffer drops its oldest event. The channel is never closed, so the
+// consumer stops on its own signal:
+//
+//	circuit := breaker.New("api", breaker.WithEventBuffer(256))
+//	go func() {
+//	    for {
+//	        select {
+//	        case e := <-circuit.Events():
+//	            sink.Send(e)
+//	        case <-done:
+//	            return
+//	        }
+//	    }
+//	}()
+//
+// Any number of goroutines can follow state changes with Subscribe. A
+// dedicated goroutine fans each change out to the subscribers, so a slow
+// one loses old events rather than holding up the circuit.
+// WithMaxStateChangeListeners caps how many may subscribe:
+//
+//	changes, unsubscribe, err := circuit.Subscribe()
+//	if err != nil {
+//	    return err
+//	}
+//	defer unsubscribe()
+//	for e := range changes {
+//	    log.Printf("%s: %s -> %s", e.Name, e.From, e.To)
+//	}
+//
+// WithTags attaches metadata such as the owning team to a circuit. Every
+// CircuitEvent, OnCallTagged hook, and Snapshot carries a copy, so one
+// shared hook can label metrics per circuit:
+//
+//	breaker.WithTags(map[string]string{"team": "billing"}),
+//	breaker.OnCallTagged(func(name string, tags map[string]string, state breaker.State, err error) {
+//	    metrics.Incr("circuit.calls", "circuit:"+name, "team:"+tags["team"])
+//	}),
+//
+// For hierarchical names, NameTags turns the parts of the name into tags,
+// so observability code need not parse names itself:
+//
+//	name := "payments/stripe/charge"
+//	breaker.New(name, breaker.WithTags(breaker.NameTags(name, "service", "provider", "op")))
+//
+// WithNamespace keeps circuits with the same name apart in a binary that
+// hosts several services. Hooks, observers, errors, and snapshots see the
+// FullName, such as "billing:database", while Name stays "database":
+//
+//	breaker.New("database", breaker.WithNamespace("billing"))
+//
+// Each circuit also has an ID, a random UUID unless set with
+// WithCircuitID. OpenError, CircuitError, Snapshot, and CircuitEvent carry
+// it, so a trace can tie a rejection to the circuit instance that caused
+// it even when every replica names its circuits alike.
+//
+// For a debug page, WithHistory keeps the last few executed calls in a
+// fixed-size buffer, and History returns a copy, oldest first:
+//
+//	circuit := breaker.New("api", breaker.WithHistory(50))
+//	for _, e := range circuit.History() {
+//	    fmt.Fprintf(w, "%s %s %v %s\n", e.At.Format(time.RFC3339), e.State, e.Duration, e.Err)
+//	}
+//
+// For an audit trail of every executed call, use WithOutcomeSink. The sink
+// runs synchronously on the call path, so keep it fast:
+//
+//	breaker.WithOutcomeSink(func(o breaker.Outcome) {
+//	    auditLog.Append(o.At, o.Name, o.State, o.Err, o.Duration)
+//	})
 //
 // # Fallback Pattern
 //
@@ -133,6 +343,114 @@
 //	    return user, err
 //	}
 //
+// Rejections after a failure are an *OpenError that wraps the error which
+// opened the circuit, so errors.Is matches both ErrOpen and the cause:
+//
+//	if errors.Is(err, breaker.ErrOpen) && errors.Is(err, io.ErrUnexpectedEOF) {
+//	    // rejected; the circuit opened on a truncated response
+//	}
+//
+// Its RetryAfter method reports how long until the circuit admits trial
+// calls:
+//
+//	var openErr *breaker.OpenError
+//	if errors.As(err, &openErr) {
+//	    w.Header().Set("Retry-After", strconv.Itoa(int(openErr.RetryAfter().Seconds())))
+//	}
+//
+// DoResult reports whether a call was a half-open trial whose failure sent
+// the circuit back to Open, which is usually worth logging louder than an
+// ordinary failure:
+//
+//	res := circuit.DoResult(ctx, callService)
+//	if res.ReopenedCircuit {
+//	    log.Warn("service still failing after recovery attempt", "err", res.Err)
+//	}
+//
+// WithRichErrors wraps fn's errors in a *CircuitError carrying the circuit
+// name, the state the call ran in, and whether the call opened the
+// circuit:
+//
+//	if err := circuit.Do(ctx, callService); breaker.IsTrip(err) {
+//	    log.Error("this call opened the circuit", "err", err)
+//	}
+//
+// DoTimed returns how long fn ran, excluding the circuit's own overhead:
+//
+//	took, err := circuit.DoTimed(ctx, callService)
+//	latency.Observe(took.Seconds())
+//
+// When a downstream says how long to back off, wrap the failure with
+// OpenFor; if it opens the circuit, that wait replaces the open duration.
+// httpbreaker.WithRetryAfterHeader does this for Retry-After headers:
+//
+//	return breaker.OpenFor(err, retryAfter)
+//
+// For functions without a return value, DoOr runs a fallback inline when the
+// circuit is open, and DoOrOnError also runs it when fn fails:
+//
+//	err := circuit.DoOr(ctx, sendEmail, queueEmail)
+//
+// # Batches
+//
+// BulkDo runs a slice of functions in order and stops calling them once the
+// circuit rejects one; the remaining slots are filled with the rejection.
+// BulkDoConcurrent runs them concurrently and stops dispatching once the
+// circuit opens:
+//
+//	errs := circuit.BulkDo(ctx, []breaker.Func{syncA, syncB, syncC})
+//
+// DoBatch instead admits a group of calls that share a fate with one
+// decision and records them as a single outcome; WithBatchPolicy chooses
+// whether any failure or only a majority fails the batch:
+//
+//	errs := circuit.DoBatch(ctx, writeA, writeB, writeC)
+//
+// # Active Probing
+//
+// By default an open circuit moves to half-open lazily, on the first call
+// after the open duration. WithActiveProbe checks the downstream in the
+// background instead, so an idle circuit reflects recovery promptly:
+//
+//	circuit := breaker.New("api",
+//	    breaker.WithActiveProbe(5*time.Second, func(ctx context.Context) error {
+//	        return client.Ping(ctx)
+//	    }),
+//	)
+//	defer circuit.Close()
+//
+// # Graceful Shutdown
+//
+// Drain waits for in-flight calls to finish while rejecting new calls with
+// ErrDraining. WithGracefulDrain bounds how long it waits:
+//
+//	circuit := breaker.New("api", breaker.WithGracefulDrain(10*time.Second))
+//
+//	// On shutdown:
+//	if err := circuit.Drain(ctx); err != nil {
+//	    log.Printf("drain incomplete: %v (%d in flight)", err, circuit.InFlight())
+//	}
+//
+// # Context Propagation
+//
+// Middleware can place a circuit in the context so nested code can use it
+// without threading it through every signature:
+//
+//	ctx = breaker.InjectCircuit(ctx, circuit)
+//
+//	// Deeper in the call stack:
+//	err := breaker.DoFromContext(ctx, func(ctx context.Context) error {
+//	    return client.Call(ctx)
+//	})
+//
+// DoFromContext returns ErrNoCircuit when the context carries no circuit.
+//
+// Code that ends up calling a circuit from inside its own Do, such as a
+// helper reached through DoFromContext, should use a separate circuit.
+// WithReentrancyCheck catches the mistake: the nested call returns
+// ErrReentrant without running, as long as it passes along the ctx that
+// the outer Do gave fn.
+//
 // # Generic Helper
 //
 // The Run function provides type-safe return values:
@@ -142,23 +460,207 @@
 //	    return doSomething(ctx)
 //	})
 //
-// This avoids the need for closures to capture return values.
+// This avoids the need for closures to capture return values. Run2 and
+// Run3 do the same for functions returning two or three values:
+//
+//	body, status, err := breaker.Run2(ctx, circuit, fetch)
+//
+// The helpers accept any Doer, so they work with a Chain or Group too.
+//
+// RunAll maps a function over a slice, one call per item. It stops at the
+// first rejection and returns the results computed so far along with the
+// open error:
+//
+//	users, err := breaker.RunAll(ctx, circuit, ids, fetchUser)
+//	if breaker.IsOpen(err) {
+//	    // users holds the results for ids[:len(users)]
+//	}
+//
+// # Manual Reporting
+//
+// When a closure is awkward, for example around a callback-based API,
+// Allow admits a call and returns a Token on which to report its outcome:
+//
+//	tok, err := circuit.Allow()
+//	if err != nil {
+//	    return err
+//	}
+//	client.Send(msg, func(err error) {
+//	    if err != nil {
+//	        tok.Fail(err)
+//	        return
+//	    }
+//	    tok.Succeed()
+//	})
+//
+// Every Token should be reported exactly once. A half-open trial held by
+// one that is dropped is given back only once the Token is garbage
+// collected.
+//
+// # Dry Run
+//
+// WithDryRun runs a circuit in observe-only mode: it counts failures,
+// changes state, and fires hooks, but never rejects. Use it to validate
+// thresholds against production traffic before enabling breaking:
+//
+//	circuit := breaker.New("api", breaker.WithDryRun(true))
+//	snap := circuit.Snapshot() // snap.State is the would-be state
+//
+// # Passthrough Mode
+//
+// Roll a circuit out behind a feature flag by creating it disabled. A
+// disabled circuit calls fn directly, tracks nothing, and fires no hooks:
+//
+//	circuit := breaker.New("api", breaker.WithDisabled())
+//
+//	if flags.Enabled("api-breaker") {
+//	    circuit.Enable()
+//	}
+//
+// Disable switches a live circuit back to passthrough, and Enabled reports
+// the current mode.
+//
+// To follow a flag on every call instead, NewConditional checks a function
+// before each call and passes the call through while it returns false.
+// Unlike Disable, turning the flag off keeps the circuit's state for when
+// it comes back on:
+//
+//	circuit := breaker.NewConditional("api", func() bool {
+//	    return flags.Enabled("api-breaker")
+//	})
+//
+// # Composite Circuits
+//
+// When one operation depends on several downstreams, a Composite reports
+// whether the operation as a whole is available:
+//
+//	checkout := breaker.NewComposite(breaker.CompositeAny, payments, inventory)
+//	if checkout.State() == breaker.Open {
+//	    return errCheckoutUnavailable
+//	}
+//
+// CompositeAny opens when any member is open; CompositeAll only when every
+// member is open.
+//
+// A Group is a named composite with its own hooks and a Snapshot of every
+// member's counts. AnyOpen trips it when any member is open; AllOpen only
+// when every member is, which suits a primary and standby:
+//
+//	db := breaker.NewGroup("db", breaker.AllOpen, []*breaker.Circuit{primary, standby},
+//	    breaker.OnStateChange(logGroupChange),
+//	)
+//
+// The group keeps no counts of its own; its state follows the members'.
+//
+// A Chain fails over between circuits, such as one per region. Each call
+// goes to the first circuit that is not open; an error from fn itself is
+// returned without trying the next one:
+//
+//	regions := breaker.NewChain(usEast, usWest)
+//	err := regions.Do(ctx, func(ctx context.Context) error {
+//	    return client.Call(ctx)
+//	})
+//
+// # Shared Failure Budgets
+//
+// Circuits hitting the same backend can share a Budget so the backend
+// opens as a unit. Every member's failures count against the budget, and
+// once it is exhausted all members open:
+//
+//	cluster := breaker.NewBudget(20)
+//	orders := breaker.New("orders", breaker.WithBudget(cluster))
+//	users := breaker.New("users", breaker.WithBudget(cluster))
 //
 // # Manual Reset
 //
-// Reset the circuit to closed state programmatically:
+// Reset the circuit to closed state programmatically. ResetAndReport
+// reports whether the circuit actually changed state, so callers can tell
+// a real recovery from a no-op:
+//
+//	if circuit.ResetAndReport() {
+//		log.Printf("circuit %s reset", circuit.Name())
+//	}
+//
+// Useful for admin endpoints or after deploying fixes. ForceOpen does the
+// opposite, taking a dependency out of rotation by hand.
+//
+// As a safety valve, WithAutoReset resets a circuit that has gone an
+// interval without closing. OnAutoReset reports when that happens, since it
+// usually means something is wrong:
+//
+//	breaker.WithAutoReset(time.Hour),
+//	breaker.OnAutoReset(func(name string) {
+//	    log.Printf("circuit %s auto-reset after an hour without closing", name)
+//	}),
 //
-//	circuit.Reset()
+// # Registry and Admin Endpoint
 //
-// Useful for admin endpoints or after deploying fixes.
+// A Registry holds circuits by name. Get creates a circuit on first use and
+// returns the same one afterwards:
+//
+//	reg := breaker.NewRegistry()
+//	circuit := reg.Get("payment-service", breaker.WithFailureThreshold(3))
+//
+// Options passed to NewRegistry apply to every circuit it creates, before
+// the options passed to Get, so Get can override them:
+//
+//	reg := breaker.NewRegistry(breaker.WithObserver(metrics), breaker.WithFailureThreshold(10))
+//	search := reg.Get("search", breaker.WithFailureThreshold(3))
+//
+// AdminHandler serves the registry's circuits as JSON and lets operators
+// reset or open them. It does no authentication, so wrap it:
+//
+//	mux.Handle("/admin/", http.StripPrefix("/admin", requireAdmin(breaker.AdminHandler(reg))))
+//
+// For a health check, Health grades one circuit as healthy (closed with no
+// failures), degraded (closed with failures, or half-open), or unhealthy
+// (open). HealthHandler serves that grade as 200, 207, or 503 with a JSON
+// body:
+//
+//	mux.Handle("/health/payments", breaker.HealthHandler(payments))
 //
 // # Inspecting State
 //
 // Query the circuit's current status:
 //
 //	state := circuit.State()    // Closed, Open, or HalfOpen
+//	open := circuit.IsOpen()    // Also IsClosed and IsHalfOpen
 //	name := circuit.Name()      // The circuit's name
-//	failures, successes := circuit.Counts()
+//	stats := circuit.Counts()       // Failures, Successes, and lifetime totals
+//	inFlight := circuit.InFlight()  // Calls currently executing
+//	err := circuit.Err()            // Last failure, such as the one that opened it
+//	snap := circuit.Snapshot()      // All of the above, consistently
+//
+// Reading state never changes it. Once the open duration has elapsed,
+// State reports HalfOpen, but the transition and its OnStateChange happen
+// on the next call through the circuit.
+//
+// Tripped is a lock-free check for hot paths that want to skip work while
+// the circuit is rejecting calls. It reads state published at each
+// transition, so it can lag a transition happening concurrently:
+//
+//	if circuit.Tripped() {
+//		return cached, nil
+//	}
+//
+// Circuits print as a one-line summary for logs:
+//
+//	log.Printf("%v", circuit) // circuit(api, state=open, failures=0/5, opened=12s ago)
+//
+// With WithLatencyWindow, Latency reports percentiles of recent call
+// durations:
+//
+//	circuit := breaker.New("api", breaker.WithLatencyWindow(1000))
+//	stats := circuit.Latency() // stats.P50, stats.P90, stats.P99
+//
+// Dashboards that poll many circuits can use WithRWMutex so reads do not
+// serialize with each other.
+//
+// Block until the circuit recovers instead of polling:
+//
+//	if err := circuit.Wait(ctx); err != nil {
+//	    return err // ctx done before the circuit closed
+//	}
 //
 // # Testing
 //
@@ -190,6 +692,41 @@
 //	    assert.Equal(t, breaker.HalfOpen, circuit.State())
 //	}
 //
+// When a test only needs to control Now, WithClockFunc adapts a plain
+// function instead; its timers, such as WithAutoReset, run in real time:
+//
+//	now := time.Now()
+//	circuit := breaker.New("test", breaker.WithClockFunc(func() time.Time { return now }))
+//
+// Timers such as WithAutoReset run in real time with a Clock that only has
+// Now. To drive them from the fake clock too, also implement AfterFunc,
+// making it a TimerClock.
+//
+// To avoid passing WithClock to every circuit in a large suite, set a
+// default once in TestMain. Production code should not call SetDefaultClock:
+//
+//	func TestMain(m *testing.M) {
+//	    breaker.SetDefaultClock(clock)
+//	    os.Exit(m.Run())
+//	}
+//
+// The testing sub-package packages this pattern with assertion helpers:
+//
+//	sim := breakertesting.NewSimulatedCircuit("test",
+//	    breaker.WithOpenDuration(30*time.Second),
+//	)
+//	breakertesting.MustTrip(t, sim.Circuit)
+//	sim.Advance(31 * time.Second)
+//	breakertesting.MustHalfOpen(t, sim.Circuit)
+//
+// For fault injection without reconfiguring a circuit, mark individual
+// calls through the context. WithForceFail records a failure without
+// running the function, and WithForceAllow runs a call the circuit would
+// reject:
+//
+//	ctx = breaker.WithForceFail(ctx)
+//	err := circuit.Do(ctx, callDependency) // ErrForcedFailure
+//
 // # Best Practices
 //
 // 1. Name circuits after the service they protect:
@@ -222,6 +759,96 @@
 //	breaker.WithHalfOpenRequests(3)
 //	breaker.WithSuccessThreshold(3)
 //
+// For high-traffic services, admit a fraction of traffic instead:
+//
+//	breaker.WithHalfOpenRatio(0.05)
+//	breaker.WithHalfOpenRequests(1000)
+//	breaker.WithSuccessThreshold(50)
+//
+// To keep trial calls from arriving in a burst, space them out:
+//
+//	breaker.WithHalfOpenRequests(3)
+//	breaker.WithProbeInterval(time.Second)
+//
+// WithFairHalfOpen hands out trial slots in arrival order rather than to
+// whichever goroutine wins the lock. Admission is serialized while the
+// circuit is not Closed, so it costs some latency under heavy contention:
+//
+//	breaker.WithHalfOpenRequests(3)
+//	breaker.WithFairHalfOpen()
+//
+// WithPriorityQueue instead favors important calls. When the trial slots
+// are full, a call with a higher WithCallPriority takes the slot of the
+// lowest-priority trial, whose context is cancelled:
+//
+//	circuit := breaker.New("api", breaker.WithPriorityQueue())
+//	ctx = breaker.WithCallPriority(ctx, 10) // health checks and VIP traffic
+//
+// To stop a circuit lingering in half-open when trial calls hang or are
+// too sparse to reach the success threshold, cap the time it spends there:
+//
+//	breaker.WithMaxHalfOpenDuration(30*time.Second)
+//
+// If trial calls keep failing, WithCooldown lengthens each Open period
+// after a failed recovery so the downstream is probed less often:
+//
+//	breaker.WithCooldown(15*time.Second)
+//
+// If the circuit instead reopens soon after closing, WithMinClosedDuration
+// keeps it closed for a while, counting failures without reopening unless
+// a WithVelocityThreshold spike says the downstream is down hard:
+//
+//	breaker.WithMinClosedDuration(time.Minute)
+//
+// When real calls are too expensive to risk as trial calls, WithProbe runs
+// a cheap health check first and only lets the call through if it passes:
+//
+//	breaker.WithProbe(func(ctx context.Context) error {
+//	    return client.Ping(ctx)
+//	})
+//
+// WithProbeFunc goes further: the probe replaces the first trial call, and
+// if it passes the circuit closes at once without waiting for the success
+// threshold:
+//
+//	breaker.WithProbeFunc(func(ctx context.Context) error {
+//	    return client.Ping(ctx)
+//	})
+//
+// A hanging trial call holds its half-open slot; bound it with a deadline:
+//
+//	breaker.WithHalfOpenProbeTimeout(2*time.Second)
+//
+// Once the circuit closes, WithRecoveryRamp caps concurrent calls and
+// raises the cap over a period so a recovering service is not flooded:
+//
+//	breaker.WithRecoveryRamp(time.Minute)
+//
+// # Integrations
+//
+// The grpcbreaker sub-package provides gRPC client interceptors:
+//
+//	conn, err := grpc.NewClient(target,
+//	    grpc.WithUnaryInterceptor(grpcbreaker.UnaryClientInterceptor(circuit)),
+//	    grpc.WithStreamInterceptor(grpcbreaker.StreamClientInterceptor(circuit)),
+//	)
+//
+// The httpbreaker sub-package wraps HTTP clients, counting transport
+// errors and 5xx responses as failures:
+//
+//	client := httpbreaker.NewHTTPClient(circuit, &http.Client{Timeout: 5 * time.Second})
+//	resp, err := client.Get(url)
+//
+// For HTTP clients that return the response inside an error, its
+// HTTPErrorCondition counts those errors by status code:
+//
+//	breaker.If(httpbreaker.HTTPErrorCondition(http.StatusServiceUnavailable))
+//
+// The hedge sub-package sends hedged requests, starting a duplicate attempt
+// through the circuit when the previous one is slow:
+//
+//	err := hedge.Do(ctx, circuit, fetchProfile, 50*time.Millisecond, 3)
+//
 // # Comparison to Other Patterns
 //
 // Circuit breaker vs retry:
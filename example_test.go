@@ -107,8 +107,8 @@ func ExampleIsOpen() {
 	// Circuit is open, using fallback
 }
 
-// ExampleCircuit_Reset demonstrates manually resetting a circuit.
-func ExampleCircuit_Reset() {
+// ExampleCircuit_ResetAndReport demonstrates manually resetting a circuit.
+func ExampleCircuit_ResetAndReport() {
 	circuit := breaker.New("service",
 		breaker.WithFailureThreshold(1),
 	)
@@ -119,13 +119,13 @@ func ExampleCircuit_Reset() {
 
 	fmt.Println("Before reset:", circuit.State())
 
-	circuit.Reset()
+	changed := circuit.ResetAndReport()
 
-	fmt.Println("After reset:", circuit.State())
+	fmt.Println("After reset:", circuit.State(), changed)
 
 	// Output:
 	// Before reset: open
-	// After reset: closed
+	// After reset: closed true
 }
 
 // ExampleIf demonstrates custom failure conditions.
@@ -0,0 +1,13 @@
+package breaker
+
+// SetTokenDropped installs fn as the hook called when a Token is collected
+// without being reported, and returns a function that removes it.
+func SetTokenDropped(fn func(name string)) (restore func()) {
+	tokenDropped.Store(&fn)
+	return func() { tokenDropped.Store(nil) }
+}
+
+// NewBatchFailure returns the error DoBatch records for a failed batch.
+func NewBatchFailure(err error) error {
+	return batchFailure{err}
+}
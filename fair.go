@@ -0,0 +1,38 @@
+package breaker
+
+import "sync"
+
+// fifoLock is a mutex that hands ownership to waiters in the order they
+// arrived. It backs WithFairHalfOpen. The zero value is unlocked.
+type fifoLock struct {
+	mu      sync.Mutex
+	held    bool
+	waiters []chan struct{}
+}
+
+func (l *fifoLock) lock() {
+	l.mu.Lock()
+	if !l.held {
+		l.held = true
+		l.mu.Unlock()
+		return
+	}
+	ch := make(chan struct{})
+	l.waiters = append(l.waiters, ch)
+	l.mu.Unlock()
+	<-ch
+}
+
+// unlock passes ownership directly to the longest waiter, if any, so a
+// newly arriving caller cannot jump the queue.
+func (l *fifoLock) unlock() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.waiters) == 0 {
+		l.held = false
+		return
+	}
+	next := l.waiters[0]
+	l.waiters = l.waiters[1:]
+	close(next)
+}
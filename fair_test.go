@@ -0,0 +1,33 @@
+package breaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFifoLock_GrantsInArrivalOrder(t *testing.T) {
+	var l fifoLock
+	l.lock()
+
+	order := make(chan int, 5)
+	for i := range 5 {
+		go func() {
+			l.lock()
+			order <- i
+			l.unlock()
+		}()
+		// Wait for the goroutine to queue before starting the next one.
+		require.Eventually(t, func() bool {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			return len(l.waiters) == i+1
+		}, time.Second, time.Millisecond)
+	}
+
+	l.unlock()
+	for want := range 5 {
+		require.Equal(t, want, <-order)
+	}
+}
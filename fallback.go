@@ -0,0 +1,28 @@
+package breaker
+
+import "context"
+
+// DoOr executes fn with circuit breaker protection, calling fallback
+// instead when the circuit rejects the call because it is open. An error
+// from fn is returned as is, even if it wraps ErrOpen. The fallback's error
+// is returned directly and does not affect the circuit.
+func (c *Circuit) DoOr(ctx context.Context, fn, fallback Func) error {
+	ran := false
+	err := c.Do(ctx, func(ctx context.Context) error {
+		ran = true
+		return fn(ctx)
+	})
+	if !ran && IsOpen(err) {
+		return fallback(ctx)
+	}
+	return err
+}
+
+// DoOrOnError is like DoOr but also calls fallback when fn fails. The
+// failure still counts against the circuit before fallback runs.
+func (c *Circuit) DoOrOnError(ctx context.Context, fn, fallback Func) error {
+	if err := c.Do(ctx, fn); err != nil {
+		return fallback(ctx)
+	}
+	return nil
+}
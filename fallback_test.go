@@ -0,0 +1,138 @@
+package breaker_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bjaus/breaker"
+	"github.com/stretchr/testify/suite"
+)
+
+var errFallback = errors.New("fallback error")
+
+type FallbackSuite struct {
+	suite.Suite
+	clock *fakeClock
+}
+
+func TestFallbackSuite(t *testing.T) {
+	suite.Run(t, new(FallbackSuite))
+}
+
+func (s *FallbackSuite) SetupTest() {
+	s.clock = newFakeClock()
+}
+
+func (s *FallbackSuite) openCircuit() *breaker.Circuit {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+	s.Require().Equal(breaker.Open, c.State())
+	return c
+}
+
+func (s *FallbackSuite) TestDoOr_SuccessSkipsFallback() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+
+	s.NoError(c.DoOr(context.Background(), func(ctx context.Context) error {
+		return nil
+	}, func(ctx context.Context) error {
+		s.Fail("fallback should not be called on success")
+		return nil
+	}))
+}
+
+func (s *FallbackSuite) TestDoOr_FailureReturnsErrorWithoutFallback() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+
+	err := c.DoOr(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}, func(ctx context.Context) error {
+		s.Fail("fallback should not be called on failure")
+		return nil
+	})
+
+	s.ErrorIs(err, errTest)
+}
+
+func (s *FallbackSuite) TestDoOr_OpenCallsFallback() {
+	c := s.openCircuit()
+
+	err := c.DoOr(context.Background(), func(ctx context.Context) error {
+		s.Fail("function should not be called when circuit is open")
+		return nil
+	}, func(ctx context.Context) error {
+		return errFallback
+	})
+
+	s.ErrorIs(err, errFallback)
+	s.False(breaker.IsOpen(err))
+}
+
+func (s *FallbackSuite) TestDoOr_DownstreamOpenErrorSkipsFallback() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+	downstream := fmt.Errorf("downstream: %w", breaker.ErrOpen)
+
+	err := c.DoOr(context.Background(), func(ctx context.Context) error {
+		return downstream
+	}, func(ctx context.Context) error {
+		s.Fail("fallback should not be called when fn ran")
+		return nil
+	})
+
+	s.ErrorIs(err, downstream)
+}
+
+func (s *FallbackSuite) TestDoOr_FallbackErrorDoesNotAffectCounts() {
+	c := s.openCircuit()
+	before := c.Snapshot()
+
+	_ = c.DoOr(context.Background(), func(ctx context.Context) error {
+		return nil
+	}, func(ctx context.Context) error {
+		return errFallback
+	})
+
+	after := c.Snapshot()
+	s.Equal(before.Failures, after.Failures)
+	s.Equal(before.State, after.State)
+}
+
+func (s *FallbackSuite) TestDoOrOnError_FailureCallsFallbackAndCounts() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+
+	s.NoError(c.DoOrOnError(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}, func(ctx context.Context) error {
+		return nil
+	}))
+
+	failures := c.Counts().Failures
+	s.Equal(1, failures)
+}
+
+func (s *FallbackSuite) TestDoOrOnError_OpenCallsFallback() {
+	c := s.openCircuit()
+
+	s.ErrorIs(c.DoOrOnError(context.Background(), func(ctx context.Context) error {
+		return nil
+	}, func(ctx context.Context) error {
+		return errFallback
+	}), errFallback)
+}
+
+func (s *FallbackSuite) TestDoOrOnError_SuccessSkipsFallback() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+
+	s.NoError(c.DoOrOnError(context.Background(), func(ctx context.Context) error {
+		return nil
+	}, func(ctx context.Context) error {
+		return errFallback
+	}))
+}
@@ -0,0 +1,195 @@
+package breaker
+
+import (
+	"context"
+	"maps"
+	"sync"
+)
+
+// GroupPolicy determines when a Group trips.
+type GroupPolicy int
+
+const (
+	// AnyOpen trips the group when any member is open. Use it when every
+	// member is required, such as a database and the cache in front of it.
+	AnyOpen GroupPolicy = iota
+
+	// AllOpen trips the group only when every member is open. Use it when
+	// members are interchangeable, such as a primary and a standby.
+	AllOpen
+)
+
+// String returns the string representation of the policy.
+func (p GroupPolicy) String() string {
+	switch p {
+	case AnyOpen:
+		return "any-open"
+	case AllOpen:
+		return "all-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Group is a named circuit composed of member circuits. It has no failure
+// counts of its own: its state is derived from the members' states on each
+// read according to its policy, and it changes only as they do. Like
+// Composite, it does not record outcomes, so fn should call through the
+// member circuits. Safe for concurrent use.
+type Group struct {
+	name     string
+	id       string
+	policy   GroupPolicy
+	circuits []*Circuit
+	cfg      config
+
+	mu   sync.Mutex
+	last State
+}
+
+// GroupSnapshot is a point-in-time view of a group and its members.
+type GroupSnapshot struct {
+	Name   string
+	Policy GroupPolicy
+	State  State
+
+	// Stats is the sum of the members' Stats.
+	Stats Stats
+
+	// Members holds one entry per member, in the order they were passed
+	// to NewGroup.
+	Members []GroupMember
+}
+
+// GroupMember is a member's entry in a GroupSnapshot. Name is the
+// member's FullName.
+type GroupMember struct {
+	Name  string
+	State State
+	Stats Stats
+}
+
+// NewGroup creates a Group named name over circuits using policy. Of the
+// options, only the observers and hooks (OnStateChange, OnReject,
+// OnRejectReason, WithObserver), WithTags, and WithCircuitID apply; the group emits
+// EventStateChange when it observes its derived state change and
+// EventReject when Do rejects a call. A Group with no members is always
+// Closed.
+func NewGroup(name string, policy GroupPolicy, circuits []*Circuit, opts ...Option) *Group {
+	g := &Group{
+		name:     name,
+		policy:   policy,
+		circuits: append([]*Circuit(nil), circuits...),
+		cfg:      newConfig(opts),
+	}
+	g.id = g.cfg.id
+	if g.id == "" {
+		g.id = newCircuitID()
+	}
+	g.last = g.aggregate()
+	return g
+}
+
+// Name returns the group's name.
+func (g *Group) Name() string {
+	return g.name
+}
+
+// ID returns the group's WithCircuitID identifier, or the random UUID
+// generated for it by NewGroup. Events the group emits carry it.
+func (g *Group) ID() string {
+	return g.id
+}
+
+// Do executes fn if the group is not open, and returns ErrOpen otherwise.
+func (g *Group) Do(ctx context.Context, fn Func) error {
+	if state := g.State(); state == Open {
+		g.emit(CircuitEvent{
+			Kind:   EventReject,
+			Name:   g.name,
+			At:     g.cfg.clock.Now(),
+			State:  state,
+			Reason: ReasonOpen,
+		})
+		return ErrOpen
+	}
+	return fn(ctx)
+}
+
+// State returns Open when the members are open according to the policy,
+// HalfOpen when any member is half-open, and Closed otherwise.
+func (g *Group) State() State {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.observe()
+}
+
+// Reset resets every member, which closes the group.
+func (g *Group) Reset() {
+	for _, m := range g.circuits {
+		m.ResetAndReport()
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.observe()
+}
+
+// Circuits returns the member circuits.
+func (g *Group) Circuits() []*Circuit {
+	return append([]*Circuit(nil), g.circuits...)
+}
+
+// Snapshot returns the group's state with each member's state and counts.
+// Members are read one at a time, so the entries are each consistent but
+// may not all be from the same instant.
+func (g *Group) Snapshot() GroupSnapshot {
+	snap := GroupSnapshot{
+		Name:    g.name,
+		Policy:  g.policy,
+		Members: make([]GroupMember, 0, len(g.circuits)),
+	}
+	for _, m := range g.circuits {
+		stats := m.Counts()
+		snap.Members = append(snap.Members, GroupMember{
+			Name:  m.FullName(),
+			State: m.State(),
+			Stats: stats,
+		})
+		snap.Stats.Failures += stats.Failures
+		snap.Stats.Successes += stats.Successes
+		snap.Stats.HalfOpenProbes += stats.HalfOpenProbes
+		snap.Stats.TotalCalls += stats.TotalCalls
+		snap.Stats.TotalRejections += stats.TotalRejections
+	}
+	snap.State = g.State()
+	return snap
+}
+
+// observe computes the group's state and emits EventStateChange if it
+// differs from the last one observed. It must be called with g.mu held.
+func (g *Group) observe() State {
+	state := g.aggregate()
+	if state != g.last {
+		g.emit(CircuitEvent{
+			Kind: EventStateChange,
+			Name: g.name,
+			At:   g.cfg.clock.Now(),
+			From: g.last,
+			To:   state,
+		})
+		g.last = state
+	}
+	return state
+}
+
+func (g *Group) aggregate() State {
+	return aggregateState(g.circuits, g.policy == AllOpen)
+}
+
+func (g *Group) emit(e CircuitEvent) {
+	e.ID = g.id
+	for _, o := range g.cfg.observers {
+		e.Tags = maps.Clone(g.cfg.tags)
+		o.Observe(e)
+	}
+}
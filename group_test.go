@@ -0,0 +1,211 @@
+package breaker_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bjaus/breaker"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type GroupSuite struct {
+	suite.Suite
+	clock *fakeClock
+}
+
+func TestGroupSuite(t *testing.T) {
+	suite.Run(t, new(GroupSuite))
+}
+
+func (s *GroupSuite) SetupTest() {
+	s.clock = newFakeClock()
+}
+
+func (s *GroupSuite) trip(c *breaker.Circuit) {
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+	s.Require().Equal(breaker.Open, c.State())
+}
+
+func (s *GroupSuite) newCircuit(name string) *breaker.Circuit {
+	return breaker.New(name,
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+}
+
+func (s *GroupSuite) TestState_Policies() {
+	tests := map[string]struct {
+		policy  breaker.GroupPolicy
+		tripped int
+		want    breaker.State
+	}{
+		"any open with none tripped": {policy: breaker.AnyOpen, tripped: 0, want: breaker.Closed},
+		"any open with one tripped":  {policy: breaker.AnyOpen, tripped: 1, want: breaker.Open},
+		"all open with one tripped":  {policy: breaker.AllOpen, tripped: 1, want: breaker.Closed},
+		"all open with both tripped": {policy: breaker.AllOpen, tripped: 2, want: breaker.Open},
+	}
+
+	for name, tc := range tests {
+		s.Run(name, func() {
+			primary, standby := s.newCircuit("primary"), s.newCircuit("standby")
+			g := breaker.NewGroup("db", tc.policy, []*breaker.Circuit{primary, standby})
+
+			for _, c := range []*breaker.Circuit{primary, standby}[:tc.tripped] {
+				s.trip(c)
+			}
+
+			s.Equal(tc.want, g.State())
+		})
+	}
+}
+
+func (s *GroupSuite) TestDo_RejectsWhenOpen() {
+	primary := s.newCircuit("primary")
+	var reasons []breaker.RejectReason
+	g := breaker.NewGroup("db", breaker.AnyOpen, []*breaker.Circuit{primary},
+		breaker.OnRejectReason(func(name string, reason breaker.RejectReason) {
+			s.Equal("db", name)
+			reasons = append(reasons, reason)
+		}),
+	)
+
+	s.trip(primary)
+
+	called := false
+	err := g.Do(context.Background(), func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	s.ErrorIs(err, breaker.ErrOpen)
+	s.False(called)
+	s.Equal([]breaker.RejectReason{breaker.ReasonOpen}, reasons)
+}
+
+func (s *GroupSuite) TestDo_RunsWhenNotOpen() {
+	primary, standby := s.newCircuit("primary"), s.newCircuit("standby")
+	g := breaker.NewGroup("db", breaker.AllOpen, []*breaker.Circuit{primary, standby})
+
+	s.trip(primary)
+
+	err := g.Do(context.Background(), func(ctx context.Context) error {
+		return standby.Do(ctx, func(ctx context.Context) error {
+			return nil
+		})
+	})
+	s.NoError(err)
+}
+
+func (s *GroupSuite) TestOnStateChange_FollowsMembers() {
+	primary, standby := s.newCircuit("primary"), s.newCircuit("standby")
+	var transitions [][2]breaker.State
+	g := breaker.NewGroup("db", breaker.AllOpen, []*breaker.Circuit{primary, standby},
+		breaker.OnStateChange(func(name string, from, to breaker.State) {
+			transitions = append(transitions, [2]breaker.State{from, to})
+		}),
+	)
+
+	s.trip(primary)
+	s.trip(standby)
+	s.Equal(breaker.Open, g.State())
+
+	g.Reset()
+	s.Equal(breaker.Closed, g.State())
+	s.True(primary.IsClosed())
+	s.True(standby.IsClosed())
+
+	s.Equal([][2]breaker.State{
+		{breaker.Closed, breaker.Open},
+		{breaker.Open, breaker.Closed},
+	}, transitions)
+}
+
+func (s *GroupSuite) TestSnapshot_AggregatesMemberStats() {
+	primary := breaker.New("primary", breaker.WithFailureThreshold(3), breaker.WithClock(s.clock))
+	standby := s.newCircuit("standby")
+	g := breaker.NewGroup("db", breaker.AnyOpen, []*breaker.Circuit{primary, standby})
+
+	for range 2 {
+		_ = primary.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		})
+	}
+	s.trip(standby)
+
+	snap := g.Snapshot()
+	s.Equal("db", snap.Name)
+	s.Equal(breaker.AnyOpen, snap.Policy)
+	s.Equal(breaker.Open, snap.State)
+	s.Equal(int64(3), snap.Stats.TotalCalls)
+	s.Equal(2, snap.Stats.Failures)
+	s.Require().Len(snap.Members, 2)
+	s.Equal("primary", snap.Members[0].Name)
+	s.Equal(breaker.Closed, snap.Members[0].State)
+	s.Equal(2, snap.Members[0].Stats.Failures)
+	s.Equal("standby", snap.Members[1].Name)
+	s.Equal(breaker.Open, snap.Members[1].State)
+}
+
+func (s *GroupSuite) TestSnapshot_UsesMemberFullNames() {
+	primary := breaker.New("primary", breaker.WithNamespace("billing"), breaker.WithClock(s.clock))
+	g := breaker.NewGroup("db", breaker.AnyOpen, []*breaker.Circuit{primary})
+
+	snap := g.Snapshot()
+
+	s.Require().Len(snap.Members, 1)
+	s.Equal(primary.Snapshot().Name, snap.Members[0].Name)
+	s.Equal("billing:primary", snap.Members[0].Name)
+}
+
+func (s *GroupSuite) TestEvents_CarryGroupID() {
+	primary := s.newCircuit("primary")
+	var events []breaker.CircuitEvent
+	g := breaker.NewGroup("db", breaker.AnyOpen, []*breaker.Circuit{primary},
+		breaker.WithCircuitID("db-1"),
+		breaker.WithObserver(breaker.ObserverFunc(func(e breaker.CircuitEvent) {
+			events = append(events, e)
+		})),
+	)
+
+	s.trip(primary)
+	s.ErrorIs(g.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}), breaker.ErrOpen)
+
+	s.Equal("db-1", g.ID())
+	s.Require().Len(events, 2)
+	for _, e := range events {
+		s.Equal("db-1", e.ID)
+	}
+}
+
+func (s *GroupSuite) TestID_GeneratedWhenUnset() {
+	a := breaker.NewGroup("db", breaker.AnyOpen, nil)
+	b := breaker.NewGroup("db", breaker.AnyOpen, nil)
+
+	s.NotEmpty(a.ID())
+	s.NotEqual(a.ID(), b.ID())
+}
+
+func (s *GroupSuite) TestState_EmptyIsClosed() {
+	s.Equal(breaker.Closed, breaker.NewGroup("empty", breaker.AllOpen, nil).State())
+}
+
+func TestGroupPolicy_String(t *testing.T) {
+	tests := map[string]struct {
+		policy breaker.GroupPolicy
+		want   string
+	}{
+		"any open": {policy: breaker.AnyOpen, want: "any-open"},
+		"all open": {policy: breaker.AllOpen, want: "all-open"},
+		"unknown":  {policy: breaker.GroupPolicy(99), want: "unknown"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.policy.String())
+		})
+	}
+}
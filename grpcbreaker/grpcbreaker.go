@@ -0,0 +1,287 @@
+// Package grpcbreaker provides gRPC client interceptors that protect unary
+// and streaming calls with a breaker.Circuit.
+//
+// Only calls failing with a qualifying status code are reported to the
+// circuit. By default these are codes.Unavailable, codes.DeadlineExceeded,
+// and codes.Internal; use WithCondition to customize:
+//
+//	conn, err := grpc.NewClient(target,
+//	    grpc.WithUnaryInterceptor(grpcbreaker.UnaryClientInterceptor(circuit,
+//	        grpcbreaker.WithCondition(grpcbreaker.GRPCCondition(codes.Unavailable)),
+//	    )),
+//	)
+//
+// When the circuit is open, calls fail with a codes.Unavailable status so
+// callers can handle them with standard gRPC tooling.
+package grpcbreaker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/bjaus/breaker"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultCodes are the status codes counted as failures by default.
+var DefaultCodes = []codes.Code{
+	codes.Unavailable,
+	codes.DeadlineExceeded,
+	codes.Internal,
+}
+
+// StreamErrorPolicy selects which stream errors are reported to the circuit.
+// Stream setup errors are always reported.
+type StreamErrorPolicy int
+
+const (
+	// StreamSetupErrors reports only errors from establishing the stream.
+	StreamSetupErrors StreamErrorPolicy = 0
+
+	// StreamRecvErrors also reports errors returned by RecvMsg.
+	StreamRecvErrors StreamErrorPolicy = 1
+
+	// StreamSendErrors also reports errors returned by SendMsg.
+	StreamSendErrors StreamErrorPolicy = 2
+)
+
+type config struct {
+	condition    breaker.Condition
+	streamPolicy StreamErrorPolicy
+}
+
+// Option configures an interceptor.
+type Option func(*config)
+
+// WithCondition sets which call errors are reported to the circuit.
+// Default is GRPCCondition(DefaultCodes...).
+func WithCondition(cond breaker.Condition) Option {
+	return func(c *config) {
+		c.condition = cond
+	}
+}
+
+// WithStreamErrorPolicy sets which mid-stream errors are reported to the
+// circuit. Policies combine with |. Default is StreamSetupErrors.
+func WithStreamErrorPolicy(policy StreamErrorPolicy) Option {
+	return func(c *config) {
+		c.streamPolicy = policy
+	}
+}
+
+// GRPCCondition returns a condition that matches errors carrying any of the
+// given status codes. Errors without a gRPC status never match.
+func GRPCCondition(codes ...codes.Code) breaker.Condition {
+	return func(err error) bool {
+		if err == nil {
+			return false
+		}
+		st, ok := status.FromError(err)
+		if !ok {
+			return false
+		}
+		for _, code := range codes {
+			if st.Code() == code {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// UnaryClientInterceptor returns an interceptor that protects unary calls
+// with c.
+func UnaryClientInterceptor(c *breaker.Circuit, opts ...Option) grpc.UnaryClientInterceptor {
+	cfg := newConfig(opts)
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		var callErr error
+		err := c.Do(ctx, func(ctx context.Context) error {
+			callErr = invoker(ctx, method, req, reply, cc, callOpts...)
+			if cfg.condition(callErr) {
+				return callErr
+			}
+			return nil
+		})
+		if breaker.IsOpen(err) {
+			return status.Error(codes.Unavailable, breaker.ErrOpen.Error())
+		}
+		if err != nil && callErr == nil {
+			return err
+		}
+		return callErr
+	}
+}
+
+// StreamClientInterceptor returns an interceptor that protects stream
+// initiation with c. Under a policy that reports mid-stream errors (see
+// WithStreamErrorPolicy), each stream is admitted once by Circuit.Do when
+// it opens and holds that admission, counting as in flight, until it
+// ends. The first reported error fails it, and a stream that completes
+// normally succeeds it. A stream ended by any other error, including the
+// cancellation of its context, is released without recording an outcome.
+// As with any gRPC stream, the caller must read it to the end or cancel
+// its context, or the admission is never released.
+func StreamClientInterceptor(c *breaker.Circuit, opts ...Option) grpc.StreamClientInterceptor {
+	cfg := newConfig(opts)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if cfg.streamPolicy != StreamSetupErrors {
+			return newClientStream(ctx, c, cfg, desc, cc, method, streamer, callOpts)
+		}
+		var stream grpc.ClientStream
+		var callErr error
+		err := c.Do(ctx, func(ctx context.Context) error {
+			stream, callErr = streamer(ctx, desc, cc, method, callOpts...)
+			if cfg.condition(callErr) {
+				return callErr
+			}
+			return nil
+		})
+		if breaker.IsOpen(err) {
+			return nil, status.Error(codes.Unavailable, breaker.ErrOpen.Error())
+		}
+		if err != nil && callErr == nil {
+			return nil, err
+		}
+		if callErr != nil {
+			return nil, callErr
+		}
+		return stream, nil
+	}
+}
+
+// clientStream is a stream whose admission lasts until the stream ends.
+// The stream runs inside a call to Circuit.Do, which returns the outcome
+// passed to settle or, if ctx ends first, one derived from ctx's error.
+type clientStream struct {
+	grpc.ClientStream
+	cfg     config
+	desc    *grpc.StreamDesc
+	once    sync.Once
+	settled chan error
+	done    chan struct{}
+}
+
+// opened is the result of starting a stream inside Circuit.Do.
+type opened struct {
+	stream   grpc.ClientStream
+	err      error
+	panicked any
+}
+
+func newClientStream(ctx context.Context, c *breaker.Circuit, cfg config, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts []grpc.CallOption) (grpc.ClientStream, error) {
+	s := &clientStream{cfg: cfg, desc: desc, settled: make(chan error, 1), done: make(chan struct{})}
+	openc := make(chan opened, 1)
+	go func() {
+		defer close(s.done)
+		sent := false
+		err := c.Do(ctx, func(ctx context.Context) error {
+			o := s.open(ctx, desc, cc, method, streamer, callOpts)
+			openc <- o
+			sent = true
+			switch {
+			case o.panicked != nil:
+				return breaker.Unrecorded(errStreamPanicked)
+			case o.err != nil:
+				return s.outcome(o.err)
+			}
+			select {
+			case err := <-s.settled:
+				return err
+			case <-ctx.Done():
+				return s.outcome(status.FromContextError(ctx.Err()).Err())
+			}
+		})
+		if !sent {
+			openc <- opened{err: err}
+		}
+	}()
+
+	o := <-openc
+	if o.panicked != nil {
+		<-s.done
+		panic(o.panicked)
+	}
+	if o.err != nil {
+		<-s.done
+		if breaker.IsOpen(o.err) {
+			return nil, status.Error(codes.Unavailable, breaker.ErrOpen.Error())
+		}
+		return nil, o.err
+	}
+	s.ClientStream = o.stream
+	return s, nil
+}
+
+// errStreamPanicked is the unrecorded outcome of a stream whose setup
+// panicked; the panic itself is raised again on the caller's goroutine.
+var errStreamPanicked = errors.New("grpcbreaker: stream setup panicked")
+
+// open starts the stream, catching a panic so it can be raised on the
+// goroutine that called the interceptor.
+func (s *clientStream) open(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts []grpc.CallOption) (o opened) {
+	defer func() {
+		if r := recover(); r != nil {
+			o = opened{panicked: r}
+		}
+	}()
+	stream, err := streamer(ctx, desc, cc, method, callOpts...)
+	return opened{stream: stream, err: err}
+}
+
+// outcome is what the stream reports to the circuit for err: err itself
+// if it qualifies, and otherwise nothing, so that an error such as a
+// cancellation neither fails the stream nor counts as a success.
+func (s *clientStream) outcome(err error) error {
+	if s.cfg.condition(err) {
+		return err
+	}
+	return breaker.Unrecorded(err)
+}
+
+// settle ends the stream's admission with err and waits for the circuit
+// to record it. Only the first call counts.
+func (s *clientStream) settle(err error) {
+	s.once.Do(func() {
+		s.settled <- err
+		<-s.done
+	})
+}
+
+func (s *clientStream) SendMsg(m any) error {
+	err := s.ClientStream.SendMsg(m)
+	if err != nil && s.cfg.streamPolicy&StreamSendErrors != 0 && s.cfg.condition(err) {
+		s.settle(err)
+	}
+	return err
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == nil:
+		if !s.desc.ServerStreams {
+			s.settle(nil)
+		}
+	case err == io.EOF:
+		s.settle(nil)
+	case s.cfg.streamPolicy&StreamRecvErrors != 0:
+		s.settle(s.outcome(err))
+	default:
+		s.settle(breaker.Unrecorded(err))
+	}
+	return err
+}
+
+func newConfig(opts []Option) config {
+	cfg := config{
+		condition: GRPCCondition(DefaultCodes...),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
@@ -0,0 +1,352 @@
+package grpcbreaker_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/bjaus/breaker"
+	"github.com/bjaus/breaker/grpcbreaker"
+	breakertesting "github.com/bjaus/breaker/testing"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type UnarySuite struct {
+	suite.Suite
+}
+
+func TestUnarySuite(t *testing.T) {
+	suite.Run(t, new(UnarySuite))
+}
+
+func invokerReturning(err error) grpc.UnaryInvoker {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return err
+	}
+}
+
+func (s *UnarySuite) call(interceptor grpc.UnaryClientInterceptor, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return interceptor(context.Background(), "/svc/Method", nil, nil, nil, invoker, opts...)
+}
+
+func (s *UnarySuite) TestCountsDefaultCodesAsFailures() {
+	for _, code := range grpcbreaker.DefaultCodes {
+		c := breaker.New("test", breaker.WithFailureThreshold(1))
+		interceptor := grpcbreaker.UnaryClientInterceptor(c)
+
+		err := s.call(interceptor, invokerReturning(status.Error(code, "boom")))
+
+		s.Equal(code, status.Code(err))
+		s.Equal(breaker.Open, c.State(), "expected %s to trip the circuit", code)
+	}
+}
+
+func (s *UnarySuite) TestIgnoresNonQualifyingCodes() {
+	c := breaker.New("test", breaker.WithFailureThreshold(1))
+	interceptor := grpcbreaker.UnaryClientInterceptor(c)
+
+	err := s.call(interceptor, invokerReturning(status.Error(codes.NotFound, "missing")))
+
+	s.Equal(codes.NotFound, status.Code(err))
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *UnarySuite) TestReturnsUnavailableWhenOpen() {
+	c := breaker.New("test", breaker.WithFailureThreshold(1))
+	interceptor := grpcbreaker.UnaryClientInterceptor(c)
+
+	_ = s.call(interceptor, invokerReturning(status.Error(codes.Unavailable, "down")))
+
+	called := false
+	err := s.call(interceptor, func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		called = true
+		return nil
+	})
+
+	s.False(called)
+	st, ok := status.FromError(err)
+	s.Require().True(ok)
+	s.Equal(codes.Unavailable, st.Code())
+	s.Equal("circuit open", st.Message())
+}
+
+func (s *UnarySuite) TestWithConditionCustomizesCodes() {
+	c := breaker.New("test", breaker.WithFailureThreshold(1))
+	interceptor := grpcbreaker.UnaryClientInterceptor(c,
+		grpcbreaker.WithCondition(grpcbreaker.GRPCCondition(codes.ResourceExhausted)),
+	)
+
+	_ = s.call(interceptor, invokerReturning(status.Error(codes.Unavailable, "down")))
+	s.Equal(breaker.Closed, c.State())
+
+	_ = s.call(interceptor, invokerReturning(status.Error(codes.ResourceExhausted, "slow down")))
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *UnarySuite) TestForwardsCallOptions() {
+	c := breaker.New("test")
+	interceptor := grpcbreaker.UnaryClientInterceptor(c)
+	opts := []grpc.CallOption{grpc.WaitForReady(true), grpc.MaxCallRecvMsgSize(1024)}
+
+	var got []grpc.CallOption
+	s.NoError(s.call(interceptor, func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, callOpts ...grpc.CallOption) error {
+		got = callOpts
+		return nil
+	}, opts...))
+
+	s.Equal(opts, got)
+}
+
+func TestGRPCCondition(t *testing.T) {
+	cond := grpcbreaker.GRPCCondition(codes.Unavailable, codes.Internal)
+
+	tests := map[string]struct {
+		err  error
+		want bool
+	}{
+		"matches listed code":       {err: status.Error(codes.Unavailable, ""), want: true},
+		"matches other listed code": {err: status.Error(codes.Internal, ""), want: true},
+		"ignores unlisted code":     {err: status.Error(codes.NotFound, ""), want: false},
+		"ignores non-status error":  {err: errors.New("plain"), want: false},
+		"ignores nil":               {err: nil, want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, cond(tc.err))
+		})
+	}
+}
+
+type fakeStream struct {
+	grpc.ClientStream
+	sendErr error
+	recvErr error
+}
+
+func (f *fakeStream) Context() context.Context { return context.Background() }
+func (f *fakeStream) SendMsg(any) error        { return f.sendErr }
+func (f *fakeStream) RecvMsg(any) error        { return f.recvErr }
+
+type StreamSuite struct {
+	suite.Suite
+}
+
+func TestStreamSuite(t *testing.T) {
+	suite.Run(t, new(StreamSuite))
+}
+
+func streamerReturning(stream grpc.ClientStream, err error) grpc.Streamer {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return stream, err
+	}
+}
+
+func (s *StreamSuite) open(interceptor grpc.StreamClientInterceptor, streamer grpc.Streamer) (grpc.ClientStream, error) {
+	return interceptor(context.Background(), &grpc.StreamDesc{}, nil, "/svc/Stream", streamer)
+}
+
+func (s *StreamSuite) TestCountsSetupFailures() {
+	c := breaker.New("test", breaker.WithFailureThreshold(1))
+	interceptor := grpcbreaker.StreamClientInterceptor(c)
+
+	_, err := s.open(interceptor, streamerReturning(nil, status.Error(codes.Unavailable, "down")))
+
+	s.Equal(codes.Unavailable, status.Code(err))
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *StreamSuite) TestReturnsUnavailableWhenOpen() {
+	c := breaker.New("test", breaker.WithFailureThreshold(1))
+	interceptor := grpcbreaker.StreamClientInterceptor(c)
+
+	_, _ = s.open(interceptor, streamerReturning(nil, status.Error(codes.Unavailable, "down")))
+	stream, err := s.open(interceptor, streamerReturning(&fakeStream{}, nil))
+
+	s.Nil(stream)
+	s.Equal(codes.Unavailable, status.Code(err))
+}
+
+func (s *StreamSuite) TestIgnoresMidStreamErrorsByDefault() {
+	c := breaker.New("test", breaker.WithFailureThreshold(1))
+	interceptor := grpcbreaker.StreamClientInterceptor(c)
+
+	stream, err := s.open(interceptor, streamerReturning(&fakeStream{
+		recvErr: status.Error(codes.Unavailable, "down"),
+		sendErr: status.Error(codes.Unavailable, "down"),
+	}, nil))
+	s.Require().NoError(err)
+
+	s.Error(stream.RecvMsg(nil))
+	s.Error(stream.SendMsg(nil))
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *StreamSuite) TestRecvPolicyCountsRecvErrors() {
+	c := breaker.New("test", breaker.WithFailureThreshold(1))
+	interceptor := grpcbreaker.StreamClientInterceptor(c,
+		grpcbreaker.WithStreamErrorPolicy(grpcbreaker.StreamRecvErrors),
+	)
+
+	stream, err := s.open(interceptor, streamerReturning(&fakeStream{
+		sendErr: status.Error(codes.Unavailable, "down"),
+	}, nil))
+	s.Require().NoError(err)
+
+	s.Error(stream.SendMsg(nil))
+	s.Equal(breaker.Closed, c.State(), "send errors should not count")
+
+	stream, err = s.open(interceptor, streamerReturning(&fakeStream{
+		recvErr: status.Error(codes.Unavailable, "down"),
+	}, nil))
+	s.Require().NoError(err)
+
+	s.Error(stream.RecvMsg(nil))
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *StreamSuite) TestSendPolicyCountsSendErrors() {
+	c := breaker.New("test", breaker.WithFailureThreshold(1))
+	interceptor := grpcbreaker.StreamClientInterceptor(c,
+		grpcbreaker.WithStreamErrorPolicy(grpcbreaker.StreamSendErrors|grpcbreaker.StreamRecvErrors),
+	)
+
+	stream, err := s.open(interceptor, streamerReturning(&fakeStream{
+		sendErr: status.Error(codes.Internal, "boom"),
+	}, nil))
+	s.Require().NoError(err)
+
+	s.Error(stream.SendMsg(nil))
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *StreamSuite) TestDoesNotCountEOF() {
+	c := breaker.New("test", breaker.WithFailureThreshold(1))
+	interceptor := grpcbreaker.StreamClientInterceptor(c,
+		grpcbreaker.WithStreamErrorPolicy(grpcbreaker.StreamRecvErrors),
+	)
+
+	stream, err := s.open(interceptor, streamerReturning(&fakeStream{recvErr: io.EOF}, nil))
+	s.Require().NoError(err)
+
+	s.ErrorIs(stream.RecvMsg(nil), io.EOF)
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *StreamSuite) TestStreamTakesOneAdmission() {
+	c := breaker.New("test", breaker.WithFailureThreshold(10))
+	interceptor := grpcbreaker.StreamClientInterceptor(c,
+		grpcbreaker.WithStreamErrorPolicy(grpcbreaker.StreamSendErrors|grpcbreaker.StreamRecvErrors),
+	)
+
+	stream, err := s.open(interceptor, streamerReturning(&fakeStream{
+		sendErr: status.Error(codes.Unavailable, "down"),
+		recvErr: status.Error(codes.Unavailable, "down"),
+	}, nil))
+	s.Require().NoError(err)
+
+	for range 3 {
+		s.Error(stream.SendMsg(nil))
+		s.Error(stream.RecvMsg(nil))
+	}
+
+	counts := c.Counts()
+	s.Equal(int64(1), counts.TotalCalls)
+	s.Equal(1, counts.Failures)
+}
+
+func (s *StreamSuite) TestReportsFailureAfterCircuitOpens() {
+	c := breaker.New("test", breaker.WithFailureThreshold(2))
+	interceptor := grpcbreaker.StreamClientInterceptor(c,
+		grpcbreaker.WithStreamErrorPolicy(grpcbreaker.StreamRecvErrors),
+	)
+	down := &fakeStream{recvErr: status.Error(codes.Unavailable, "down")}
+
+	first, err := s.open(interceptor, streamerReturning(down, nil))
+	s.Require().NoError(err)
+	second, err := s.open(interceptor, streamerReturning(down, nil))
+	s.Require().NoError(err)
+
+	s.Error(first.RecvMsg(nil))
+	s.Error(second.RecvMsg(nil))
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *StreamSuite) TestSuccessfulStreamClosesHalfOpenCircuit() {
+	sim := breakertesting.NewSimulatedCircuit("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Second),
+		breaker.WithSuccessThreshold(1),
+		breaker.WithHalfOpenRequests(1),
+	)
+	interceptor := grpcbreaker.StreamClientInterceptor(sim.Circuit,
+		grpcbreaker.WithStreamErrorPolicy(grpcbreaker.StreamRecvErrors),
+	)
+	breakertesting.MustTrip(s.T(), sim.Circuit)
+	sim.Advance(time.Second)
+	breakertesting.MustHalfOpen(s.T(), sim.Circuit)
+
+	stream, err := s.open(interceptor, streamerReturning(&fakeStream{recvErr: io.EOF}, nil))
+	s.Require().NoError(err)
+
+	s.ErrorIs(stream.RecvMsg(nil), io.EOF)
+	breakertesting.MustClose(s.T(), sim.Circuit)
+}
+
+func (s *StreamSuite) TestOpenStreamCountsAsInFlight() {
+	c := breaker.New("test")
+	interceptor := grpcbreaker.StreamClientInterceptor(c,
+		grpcbreaker.WithStreamErrorPolicy(grpcbreaker.StreamRecvErrors),
+	)
+
+	stream, err := s.open(interceptor, streamerReturning(&fakeStream{recvErr: io.EOF}, nil))
+	s.Require().NoError(err)
+	s.Equal(1, c.InFlight())
+
+	s.ErrorIs(stream.RecvMsg(nil), io.EOF)
+	s.Equal(0, c.InFlight())
+}
+
+func (s *StreamSuite) TestCanceledStreamIsNotCounted() {
+	c := breaker.New("test", breaker.WithFailureThreshold(2))
+	interceptor := grpcbreaker.StreamClientInterceptor(c,
+		grpcbreaker.WithStreamErrorPolicy(grpcbreaker.StreamRecvErrors),
+	)
+	down := &fakeStream{recvErr: status.Error(codes.Unavailable, "down")}
+	canceled := &fakeStream{recvErr: status.Error(codes.Canceled, "canceled")}
+
+	for _, fake := range []*fakeStream{down, canceled, down} {
+		stream, err := s.open(interceptor, streamerReturning(fake, nil))
+		s.Require().NoError(err)
+		s.Error(stream.RecvMsg(nil))
+	}
+
+	s.Equal(breaker.Open, c.State(), "a canceled stream should not reset the failure streak")
+}
+
+func (s *StreamSuite) TestContextEndSettlesStream() {
+	c := breaker.New("test", breaker.WithFailureThreshold(2))
+	interceptor := grpcbreaker.StreamClientInterceptor(c,
+		grpcbreaker.WithStreamErrorPolicy(grpcbreaker.StreamRecvErrors),
+	)
+	down, err := s.open(interceptor, streamerReturning(&fakeStream{
+		recvErr: status.Error(codes.Unavailable, "down"),
+	}, nil))
+	s.Require().NoError(err)
+	s.Error(down.RecvMsg(nil))
+	ctx, cancel := context.WithCancel(context.Background())
+
+	_, err = interceptor(ctx, &grpc.StreamDesc{}, nil, "/svc/Stream", streamerReturning(&fakeStream{}, nil))
+	s.Require().NoError(err)
+	s.Equal(1, c.InFlight())
+
+	cancel()
+	s.Eventually(func() bool { return c.InFlight() == 0 }, time.Second, time.Millisecond)
+	s.Equal(1, c.Counts().Failures, "a canceled context should not reset the failure streak")
+}
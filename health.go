@@ -0,0 +1,95 @@
+package breaker
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// HealthStatus summarizes a circuit's state for health checks.
+type HealthStatus int
+
+const (
+	// HealthStatusHealthy means the circuit is closed with no failures.
+	HealthStatusHealthy HealthStatus = iota
+
+	// HealthStatusDegraded means the circuit is closed but counting
+	// failures, or half-open and testing recovery.
+	HealthStatusDegraded
+
+	// HealthStatusUnhealthy means the circuit is open.
+	HealthStatusUnhealthy
+)
+
+// String returns the string representation of the status.
+func (h HealthStatus) String() string {
+	switch h {
+	case HealthStatusHealthy:
+		return "healthy"
+	case HealthStatusDegraded:
+		return "degraded"
+	case HealthStatusUnhealthy:
+		return "unhealthy"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalJSON encodes the status as its string representation.
+func (h HealthStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.String())
+}
+
+// Health reports the circuit's health: HealthStatusHealthy when it is
+// Closed with no failures, HealthStatusDegraded when it is Closed with
+// failures or HalfOpen, and HealthStatusUnhealthy when it is Open. Like
+// State, it does not change the circuit.
+func (c *Circuit) Health() HealthStatus {
+	health, _ := c.health()
+	return health
+}
+
+// health returns the circuit's health along with the state it is based on.
+func (c *Circuit) health() (HealthStatus, State) {
+	c.rlock()
+	defer c.runlock()
+	state := c.readState()
+	switch {
+	case state == Open:
+		return HealthStatusUnhealthy, state
+	case state == HalfOpen || c.failures > 0:
+		return HealthStatusDegraded, state
+	default:
+		return HealthStatusHealthy, state
+	}
+}
+
+// HealthHandler returns an http.Handler that reports c's Health for a
+// health check endpoint. It responds 200 when healthy, 207 when degraded,
+// and 503 when unhealthy, with a JSON body such as:
+//
+//	{"name":"payments","status":"degraded","state":"closed"}
+func HealthHandler(c *Circuit) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		health, state := c.health()
+
+		status := http.StatusOK
+		switch health {
+		case HealthStatusDegraded:
+			status = http.StatusMultiStatus
+		case HealthStatusUnhealthy:
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, healthResponse{
+			Name:   c.fullName,
+			Status: health,
+			State:  state.String(),
+		})
+	})
+}
+
+// healthResponse is the JSON body written by HealthHandler.
+type healthResponse struct {
+	Name   string       `json:"name"`
+	Status HealthStatus `json:"status"`
+	State  string       `json:"state"`
+}
@@ -0,0 +1,101 @@
+package breaker_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bjaus/breaker"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type HealthSuite struct {
+	suite.Suite
+	clock *fakeClock
+}
+
+func TestHealthSuite(t *testing.T) {
+	suite.Run(t, new(HealthSuite))
+}
+
+func (s *HealthSuite) SetupTest() {
+	s.clock = newFakeClock()
+}
+
+func (s *HealthSuite) fail(c *breaker.Circuit) {
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+}
+
+func (s *HealthSuite) TestHealth_FollowsStateAndFailures() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(2),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithClock(s.clock),
+	)
+	s.Equal(breaker.HealthStatusHealthy, c.Health())
+
+	s.fail(c)
+	s.Equal(breaker.HealthStatusDegraded, c.Health(), "closed with failures")
+
+	s.fail(c)
+	s.Equal(breaker.HealthStatusUnhealthy, c.Health())
+
+	s.clock.Advance(time.Minute)
+	s.Equal(breaker.HealthStatusDegraded, c.Health(), "half-open")
+}
+
+func (s *HealthSuite) TestHealthHandler_RespondsWithStatusAndJSON() {
+	c := breaker.New("payments",
+		breaker.WithFailureThreshold(2),
+		breaker.WithClock(s.clock),
+	)
+	handler := breaker.HealthHandler(c)
+
+	tests := []struct {
+		status int
+		body   string
+	}{
+		{http.StatusOK, `{"name":"payments","status":"healthy","state":"closed"}`},
+		{http.StatusMultiStatus, `{"name":"payments","status":"degraded","state":"closed"}`},
+		{http.StatusServiceUnavailable, `{"name":"payments","status":"unhealthy","state":"open"}`},
+	}
+	for i, tc := range tests {
+		if i > 0 {
+			s.fail(c)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+		s.Equal(tc.status, rec.Code)
+		s.Equal("application/json", rec.Header().Get("Content-Type"))
+		s.JSONEq(tc.body, rec.Body.String())
+	}
+}
+
+func TestHealthStatus_String(t *testing.T) {
+	tests := map[string]struct {
+		status breaker.HealthStatus
+		want   string
+	}{
+		"healthy":   {status: breaker.HealthStatusHealthy, want: "healthy"},
+		"degraded":  {status: breaker.HealthStatusDegraded, want: "degraded"},
+		"unhealthy": {status: breaker.HealthStatusUnhealthy, want: "unhealthy"},
+		"unknown":   {status: breaker.HealthStatus(99), want: "unknown"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.status.String())
+
+			data, err := json.Marshal(tc.status)
+			require.NoError(t, err)
+			require.JSONEq(t, `"`+tc.want+`"`, string(data))
+		})
+	}
+}
@@ -0,0 +1,98 @@
+// Package hedge provides hedged calls through a breaker.Circuit.
+//
+// A hedged call starts a duplicate attempt when the previous one has not
+// returned within a delay, and takes whichever attempt succeeds first:
+//
+//	err := hedge.Do(ctx, circuit, fetchProfile, 50*time.Millisecond, 3)
+//
+// Every attempt runs through the circuit, so each one is admitted, counted,
+// and rejected like any other call.
+package hedge
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bjaus/breaker"
+)
+
+// errLost is the cause given to attempts cancelled because another one
+// succeeded.
+var errLost = errors.New("hedge: another attempt succeeded")
+
+// Do calls fn through c, launching up to n attempts. A new attempt starts
+// when the previous one has not returned within delay, or immediately when
+// an attempt fails. Do returns nil as soon as any attempt succeeds and
+// cancels the others. If every attempt fails, Do returns the first error.
+//
+// Attempts cancelled because another one succeeded are released without
+// recording an outcome, whatever the circuit's condition, so hedging never
+// counts its losers as failures. A rejected attempt stops further
+// launches, since the circuit would reject them too.
+func Do(ctx context.Context, c *breaker.Circuit, fn breaker.Func, delay time.Duration, n int) error {
+	n = max(n, 1)
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(errLost)
+
+	results := make(chan error, n)
+	launch := func() {
+		go func() {
+			var fnErr error
+			err := c.Do(ctx, func(ctx context.Context) error {
+				fnErr = fn(ctx)
+				switch {
+				case fnErr == nil:
+					return nil
+				case context.Cause(ctx) == errLost:
+					return breaker.Unrecorded(fnErr)
+				case ctx.Err() != nil:
+					return ctx.Err()
+				}
+				return fnErr
+			})
+			if err != nil && fnErr != nil {
+				err = fnErr
+			}
+			results <- err
+		}()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	launch()
+	launched, pending := 1, 1
+	var firstErr error
+	for pending > 0 {
+		select {
+		case err := <-results:
+			pending--
+			if err == nil {
+				return nil
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			if breaker.IsOpen(err) {
+				n = launched
+			}
+			if launched < n {
+				launch()
+				launched++
+				pending++
+				timer.Reset(delay)
+			}
+
+		case <-timer.C:
+			if launched < n {
+				launch()
+				launched++
+				pending++
+				timer.Reset(delay)
+			}
+		}
+	}
+	return firstErr
+}
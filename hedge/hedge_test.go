@@ -0,0 +1,157 @@
+package hedge_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bjaus/breaker"
+	"github.com/bjaus/breaker/hedge"
+	"github.com/stretchr/testify/suite"
+)
+
+var errTest = errors.New("test error")
+
+type HedgeSuite struct {
+	suite.Suite
+}
+
+func TestHedgeSuite(t *testing.T) {
+	suite.Run(t, new(HedgeSuite))
+}
+
+func (s *HedgeSuite) TestReturnsFirstSuccessWithoutHedging() {
+	c := breaker.New("test")
+
+	var calls atomic.Int32
+	err := hedge.Do(context.Background(), c, func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	}, time.Hour, 3)
+
+	s.NoError(err)
+	s.Equal(int32(1), calls.Load())
+}
+
+func (s *HedgeSuite) TestHedgesSlowAttempt() {
+	c := breaker.New("test")
+
+	var calls atomic.Int32
+	err := hedge.Do(context.Background(), c, func(ctx context.Context) error {
+		if calls.Add(1) == 1 {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	}, time.Millisecond, 2)
+
+	s.NoError(err)
+	s.Equal(int32(2), calls.Load())
+}
+
+func (s *HedgeSuite) TestCancelledAttemptsDoNotCountAsFailures() {
+	c := breaker.New("test", breaker.WithFailureThreshold(1))
+
+	var calls atomic.Int32
+	cancelled := make(chan struct{})
+	err := hedge.Do(context.Background(), c, func(ctx context.Context) error {
+		if calls.Add(1) == 1 {
+			<-ctx.Done()
+			defer close(cancelled)
+			return errTest
+		}
+		return nil
+	}, time.Millisecond, 2)
+	s.Require().NoError(err)
+
+	<-cancelled
+	s.Eventually(func() bool {
+		return c.InFlight() == 0
+	}, time.Second, time.Millisecond)
+	s.Equal(breaker.Closed, c.State())
+	failures := c.Counts().Failures
+	s.Zero(failures)
+}
+
+func (s *HedgeSuite) TestCancelledAttemptsIgnoreCustomCondition() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.If(func(err error) bool { return err != nil }),
+	)
+
+	var calls atomic.Int32
+	cancelled := make(chan struct{})
+	err := hedge.Do(context.Background(), c, func(ctx context.Context) error {
+		if calls.Add(1) == 1 {
+			<-ctx.Done()
+			defer close(cancelled)
+			return ctx.Err()
+		}
+		return nil
+	}, time.Millisecond, 2)
+	s.Require().NoError(err)
+
+	<-cancelled
+	s.Eventually(func() bool {
+		return c.InFlight() == 0
+	}, time.Second, time.Millisecond)
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *HedgeSuite) TestLaunchesNextAttemptImmediatelyOnFailure() {
+	c := breaker.New("test", breaker.WithFailureThreshold(10))
+
+	var calls atomic.Int32
+	err := hedge.Do(context.Background(), c, func(ctx context.Context) error {
+		if calls.Add(1) < 3 {
+			return errTest
+		}
+		return nil
+	}, time.Hour, 3)
+
+	s.NoError(err)
+	s.Equal(int32(3), calls.Load())
+}
+
+func (s *HedgeSuite) TestReturnsFirstErrorWhenAllAttemptsFail() {
+	c := breaker.New("test", breaker.WithFailureThreshold(10))
+
+	var calls atomic.Int32
+	err := hedge.Do(context.Background(), c, func(ctx context.Context) error {
+		calls.Add(1)
+		return errTest
+	}, time.Hour, 3)
+
+	s.ErrorIs(err, errTest)
+	s.Equal(int32(3), calls.Load())
+}
+
+func (s *HedgeSuite) TestStopsLaunchingWhenCircuitOpens() {
+	c := breaker.New("test", breaker.WithFailureThreshold(1))
+
+	var calls atomic.Int32
+	err := hedge.Do(context.Background(), c, func(ctx context.Context) error {
+		calls.Add(1)
+		return errTest
+	}, time.Hour, 5)
+
+	s.ErrorIs(err, errTest)
+	s.Equal(int32(1), calls.Load())
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *HedgeSuite) TestRejectedWhenOpen() {
+	c := breaker.New("test", breaker.WithFailureThreshold(1))
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+
+	err := hedge.Do(context.Background(), c, func(ctx context.Context) error {
+		s.Fail("function should not be called when circuit is open")
+		return nil
+	}, time.Millisecond, 3)
+
+	s.True(breaker.IsOpen(err))
+}
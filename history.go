@@ -0,0 +1,72 @@
+package breaker
+
+import (
+	"sync"
+	"time"
+)
+
+// HistoryEntry describes one executed call, as kept by WithHistory.
+type HistoryEntry struct {
+	// At is when the call completed, per the circuit's clock.
+	At time.Time
+
+	// State is the state the circuit was in when the call was admitted.
+	State State
+
+	// Err is the message of the error the call returned, or empty if it
+	// succeeded.
+	Err string
+
+	Duration time.Duration
+}
+
+// historyRing holds the most recent history entries. Like latencyRing, it
+// has its own lock so recording does not contend with state changes.
+type historyRing struct {
+	mu   sync.Mutex
+	buf  []HistoryEntry
+	next int
+	full bool
+}
+
+func newHistoryRing(size int) *historyRing {
+	return &historyRing{buf: make([]HistoryEntry, size)}
+}
+
+func (r *historyRing) add(e HistoryEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buf[r.next] = e
+	r.next++
+	if r.next == len(r.buf) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+// entries returns the entries oldest first.
+func (r *historyRing) entries() []HistoryEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.full {
+		return append([]HistoryEntry(nil), r.buf[:r.next]...)
+	}
+	out := make([]HistoryEntry, 0, len(r.buf))
+	out = append(out, r.buf[r.next:]...)
+	return append(out, r.buf[:r.next]...)
+}
+
+// History returns the most recent calls executed through the circuit,
+// oldest first, as configured by WithHistory. It returns nil if
+// WithHistory is not set. Rejected calls are not included.
+//
+// The history is meant for people reading a debug page, not for metrics.
+// It is approximate under heavy concurrency: calls that finish together
+// may be listed in a different order than they completed, and a call may
+// appear shortly after the state change it caused.
+func (c *Circuit) History() []HistoryEntry {
+	if c.history == nil {
+		return nil
+	}
+	return c.history.entries()
+}
@@ -0,0 +1,118 @@
+package breaker_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bjaus/breaker"
+	"github.com/stretchr/testify/suite"
+)
+
+type HistorySuite struct {
+	suite.Suite
+	clock *fakeClock
+}
+
+func TestHistorySuite(t *testing.T) {
+	suite.Run(t, new(HistorySuite))
+}
+
+func (s *HistorySuite) SetupTest() {
+	s.clock = newFakeClock()
+}
+
+func (s *HistorySuite) TestHistory_RecordsExecutedCallsOldestFirst() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(2),
+		breaker.WithHistory(10),
+		breaker.WithClock(s.clock),
+	)
+	start := s.clock.Now()
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		s.clock.Advance(time.Second)
+		return nil
+	})
+	for range 2 {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			s.clock.Advance(2 * time.Second)
+			return errTest
+		})
+	}
+	s.True(breaker.IsOpen(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	})))
+
+	s.Equal([]breaker.HistoryEntry{
+		{At: start.Add(time.Second), State: breaker.Closed, Duration: time.Second},
+		{At: start.Add(3 * time.Second), State: breaker.Closed, Err: errTest.Error(), Duration: 2 * time.Second},
+		{At: start.Add(5 * time.Second), State: breaker.Closed, Err: errTest.Error(), Duration: 2 * time.Second},
+	}, c.History())
+}
+
+func (s *HistorySuite) TestHistory_KeepsOnlyRecentCalls() {
+	c := breaker.New("test",
+		breaker.WithHistory(3),
+		breaker.WithClock(s.clock),
+	)
+
+	for i := 1; i <= 5; i++ {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			s.clock.Advance(time.Duration(i) * time.Millisecond)
+			return nil
+		})
+	}
+
+	var durations []time.Duration
+	for _, e := range c.History() {
+		durations = append(durations, e.Duration)
+	}
+	s.Equal([]time.Duration{3 * time.Millisecond, 4 * time.Millisecond, 5 * time.Millisecond}, durations)
+}
+
+func (s *HistorySuite) TestHistory_ReturnsCopy() {
+	c := breaker.New("test",
+		breaker.WithHistory(3),
+		breaker.WithClock(s.clock),
+	)
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+
+	c.History()[0].Err = "changed"
+
+	s.Equal(errTest.Error(), c.History()[0].Err)
+}
+
+func (s *HistorySuite) TestHistory_NilWithoutOption() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	})
+
+	s.Nil(c.History())
+}
+
+func (s *HistorySuite) TestHistory_ConcurrentCallsStayBounded() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1000),
+		breaker.WithHistory(50),
+	)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			for range 100 {
+				_ = c.Do(context.Background(), func(ctx context.Context) error {
+					return nil
+				})
+				_ = c.History()
+			}
+		})
+	}
+	wg.Wait()
+
+	s.Len(c.History(), 50)
+}
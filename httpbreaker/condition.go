@@ -0,0 +1,48 @@
+package httpbreaker
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/bjaus/breaker"
+)
+
+// ConditionFromHTTPResponse returns a breaker.Condition for HTTP clients
+// that carry the response inside their errors. extract returns the
+// response from an error, if it has one. An error with a response counts
+// as a failure only if its status code is one of statusCodes, or of
+// DefaultStatusCodes if none are given. An error without a response, such
+// as a transport error, always counts, and nil never does.
+func ConditionFromHTTPResponse(extract func(error) (*http.Response, bool), statusCodes ...int) breaker.Condition {
+	if len(statusCodes) == 0 {
+		statusCodes = DefaultStatusCodes
+	}
+	statusCodes = slices.Clone(statusCodes)
+	return func(err error) bool {
+		if err == nil {
+			return false
+		}
+		resp, ok := extract(err)
+		if !ok || resp == nil {
+			return true
+		}
+		return slices.Contains(statusCodes, resp.StatusCode)
+	}
+}
+
+// HTTPErrorCondition is ConditionFromHTTPResponse for errors that provide
+// their response through a Response method, as many HTTP client libraries'
+// error types do. It finds such an error anywhere in the chain with
+// errors.As.
+func HTTPErrorCondition(statusCodes ...int) breaker.Condition {
+	return ConditionFromHTTPResponse(responseFromError, statusCodes...)
+}
+
+func responseFromError(err error) (*http.Response, bool) {
+	var re interface{ Response() *http.Response }
+	if !errors.As(err, &re) {
+		return nil, false
+	}
+	return re.Response(), true
+}
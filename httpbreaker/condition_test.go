@@ -0,0 +1,68 @@
+package httpbreaker_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/bjaus/breaker/httpbreaker"
+	"github.com/stretchr/testify/require"
+)
+
+type responseError struct {
+	resp *http.Response
+}
+
+func (e *responseError) Error() string {
+	return "request failed"
+}
+
+func (e *responseError) Response() *http.Response {
+	return e.resp
+}
+
+func withStatus(code int) error {
+	return &responseError{resp: &http.Response{StatusCode: code}}
+}
+
+func TestHTTPErrorCondition(t *testing.T) {
+	tests := map[string]struct {
+		codes []int
+		err   error
+		want  bool
+	}{
+		"nil error":                  {err: nil, want: false},
+		"transport error":            {err: errTransport, want: true},
+		"default failing status":     {err: withStatus(http.StatusBadGateway), want: true},
+		"default passing status":     {err: withStatus(http.StatusNotFound), want: false},
+		"wrapped response error":     {err: fmt.Errorf("get: %w", withStatus(http.StatusServiceUnavailable)), want: true},
+		"custom codes match":         {codes: []int{http.StatusTooManyRequests}, err: withStatus(http.StatusTooManyRequests), want: true},
+		"custom codes replace":       {codes: []int{http.StatusTooManyRequests}, err: withStatus(http.StatusBadGateway), want: false},
+		"response error without one": {err: &responseError{}, want: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cond := httpbreaker.HTTPErrorCondition(tc.codes...)
+			require.Equal(t, tc.want, cond(tc.err))
+		})
+	}
+}
+
+func TestConditionFromHTTPResponse_UsesExtractor(t *testing.T) {
+	type clientError struct {
+		error
+		resp *http.Response
+	}
+	cond := httpbreaker.ConditionFromHTTPResponse(func(err error) (*http.Response, bool) {
+		var ce clientError
+		if errors.As(err, &ce) {
+			return ce.resp, true
+		}
+		return nil, false
+	}, http.StatusInternalServerError)
+
+	require.True(t, cond(clientError{error: errors.New("boom"), resp: &http.Response{StatusCode: http.StatusInternalServerError}}))
+	require.False(t, cond(clientError{error: errors.New("boom"), resp: &http.Response{StatusCode: http.StatusBadRequest}}))
+}
@@ -0,0 +1,172 @@
+// Package httpbreaker protects HTTP clients with a breaker.Circuit.
+//
+// Transport errors always count as failures. Responses count as failures
+// when their status code is one of DefaultStatusCodes; use WithStatusCodes
+// to customize:
+//
+//	client := httpbreaker.NewHTTPClient(circuit, &http.Client{Timeout: 5 * time.Second},
+//	    httpbreaker.WithStatusCodes(http.StatusServiceUnavailable),
+//	)
+//
+// A failing response is still returned to the caller. When the circuit is
+// open, requests fail without being sent, with an error matching
+// breaker.ErrOpen.
+//
+// For clients that wrap their own transport and return the response inside
+// an error, HTTPErrorCondition and ConditionFromHTTPResponse build a
+// breaker.Condition from the same status codes:
+//
+//	circuit := breaker.New("api", breaker.If(httpbreaker.HTTPErrorCondition()))
+package httpbreaker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/bjaus/breaker"
+)
+
+// DefaultStatusCodes are the response status codes counted as failures by
+// default.
+var DefaultStatusCodes = []int{
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// StatusError is reported to the circuit for a response with a failing
+// status code. It is never returned to the caller.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("http status %d", e.StatusCode)
+}
+
+type config struct {
+	statusCodes []int
+	retryAfter  bool
+}
+
+// Option configures a RoundTripper.
+type Option func(*config)
+
+// WithStatusCodes sets which response status codes count as failures.
+// Default is DefaultStatusCodes.
+func WithStatusCodes(codes ...int) Option {
+	return func(c *config) {
+		c.statusCodes = codes
+	}
+}
+
+// WithRetryAfterHeader makes a failing response's Retry-After header, in
+// either delay-seconds or HTTP-date form, set how long the circuit stays
+// open if that response opens it. Without the header, or if it cannot be
+// parsed, the circuit's open duration applies. Servers usually send the
+// header with 429 and 503 responses; add http.StatusTooManyRequests with
+// WithStatusCodes to count the former.
+func WithRetryAfterHeader() Option {
+	return func(c *config) {
+		c.retryAfter = true
+	}
+}
+
+// NewRoundTripper returns a RoundTripper that sends requests through base
+// with c. If base is nil, http.DefaultTransport is used.
+func NewRoundTripper(c *breaker.Circuit, base http.RoundTripper, opts ...Option) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &roundTripper{circuit: c, base: base, cfg: newConfig(opts)}
+}
+
+type roundTripper struct {
+	circuit *breaker.Circuit
+	base    http.RoundTripper
+	cfg     config
+}
+
+func (t *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	var resp *http.Response
+	var respErr error
+	err := t.circuit.Do(req.Context(), func(ctx context.Context) error {
+		resp, respErr = t.base.RoundTrip(req)
+		if respErr != nil {
+			return respErr
+		}
+		if !slices.Contains(t.cfg.statusCodes, resp.StatusCode) {
+			return nil
+		}
+		var err error = &StatusError{StatusCode: resp.StatusCode}
+		if t.cfg.retryAfter {
+			if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				err = breaker.OpenFor(err, d)
+			}
+		}
+		return err
+	})
+	if resp == nil && respErr == nil {
+		return nil, err
+	}
+	return resp, respErr
+}
+
+// parseRetryAfter parses a Retry-After header value, which RFC 9110 allows
+// as either a number of seconds or an HTTP-date, relative to now.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs <= 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	at, err := http.ParseTime(value)
+	if err != nil || !at.After(now) {
+		return 0, false
+	}
+	return at.Sub(now), true
+}
+
+// CircuitClient is an *http.Client whose transport is protected by a
+// circuit. It can be used anywhere the embedded client's methods are.
+type CircuitClient struct {
+	*http.Client
+	circuit *breaker.Circuit
+}
+
+// Circuit returns the circuit protecting the client.
+func (c *CircuitClient) Circuit() *breaker.Circuit {
+	return c.circuit
+}
+
+// NewHTTPClient returns a copy of base whose transport is wrapped with
+// NewRoundTripper. Timeout, redirect policy, and cookie jar are preserved.
+// If base is nil, http.DefaultClient is used as the template. Pass
+// CircuitClient.Client where an *http.Client is required.
+func NewHTTPClient(c *breaker.Circuit, base *http.Client, opts ...Option) *CircuitClient {
+	if base == nil {
+		base = http.DefaultClient
+	}
+	client := *base
+	client.Transport = NewRoundTripper(c, base.Transport, opts...)
+	return &CircuitClient{Client: &client, circuit: c}
+}
+
+func newConfig(opts []Option) config {
+	cfg := config{
+		statusCodes: DefaultStatusCodes,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
@@ -0,0 +1,242 @@
+package httpbreaker_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bjaus/breaker"
+	"github.com/bjaus/breaker/httpbreaker"
+	breakertesting "github.com/bjaus/breaker/testing"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+var errTransport = errors.New("connection refused")
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func respondWith(code int) http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: code, Body: http.NoBody, Request: req}, nil
+	})
+}
+
+type RoundTripperSuite struct {
+	suite.Suite
+}
+
+func TestRoundTripperSuite(t *testing.T) {
+	suite.Run(t, new(RoundTripperSuite))
+}
+
+func (s *RoundTripperSuite) send(rt http.RoundTripper) (*http.Response, error) {
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	return rt.RoundTrip(req)
+}
+
+func (s *RoundTripperSuite) TestCountsDefaultStatusCodesAsFailures() {
+	for _, code := range httpbreaker.DefaultStatusCodes {
+		c := breaker.New("test", breaker.WithFailureThreshold(1))
+
+		resp, err := s.send(httpbreaker.NewRoundTripper(c, respondWith(code)))
+
+		s.Require().NoError(err)
+		s.Equal(code, resp.StatusCode, "expected the failing response to be returned")
+		s.Equal(breaker.Open, c.State(), "expected %d to trip the circuit", code)
+	}
+}
+
+func (s *RoundTripperSuite) TestIgnoresOtherStatusCodes() {
+	c := breaker.New("test", breaker.WithFailureThreshold(1))
+
+	resp, err := s.send(httpbreaker.NewRoundTripper(c, respondWith(http.StatusNotFound)))
+
+	s.Require().NoError(err)
+	s.Equal(http.StatusNotFound, resp.StatusCode)
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *RoundTripperSuite) TestCountsTransportErrors() {
+	c := breaker.New("test", breaker.WithFailureThreshold(1))
+	rt := httpbreaker.NewRoundTripper(c, roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errTransport
+	}))
+
+	resp, err := s.send(rt)
+
+	s.Nil(resp)
+	s.ErrorIs(err, errTransport)
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *RoundTripperSuite) TestRejectsWhenOpen() {
+	c := breaker.New("test", breaker.WithFailureThreshold(1))
+	c.ForceOpen()
+	sent := false
+	rt := httpbreaker.NewRoundTripper(c, roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sent = true
+		return nil, nil
+	}))
+
+	resp, err := s.send(rt)
+
+	s.Nil(resp)
+	s.True(breaker.IsOpen(err))
+	s.False(sent)
+}
+
+func (s *RoundTripperSuite) TestWithStatusCodes() {
+	c := breaker.New("test", breaker.WithFailureThreshold(1))
+	rt := httpbreaker.NewRoundTripper(c, respondWith(http.StatusTooManyRequests),
+		httpbreaker.WithStatusCodes(http.StatusTooManyRequests),
+	)
+
+	_, err := s.send(rt)
+
+	s.Require().NoError(err)
+	s.Equal(breaker.Open, c.State())
+}
+
+func respondWithRetryAfter(code int, retryAfter string) http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		header := http.Header{}
+		header.Set("Retry-After", retryAfter)
+		return &http.Response{StatusCode: code, Header: header, Body: http.NoBody, Request: req}, nil
+	})
+}
+
+func (s *RoundTripperSuite) openFor(c *breaker.Circuit) time.Duration {
+	var openErr *breaker.OpenError
+	_, err := s.send(httpbreaker.NewRoundTripper(c, respondWith(http.StatusOK)))
+	s.Require().ErrorAs(err, &openErr)
+	return openErr.OpenDuration
+}
+
+func (s *RoundTripperSuite) TestRetryAfterHeader_Seconds() {
+	clock := breakertesting.NewClock()
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Second),
+		breaker.WithClock(clock),
+	)
+	rt := httpbreaker.NewRoundTripper(c, respondWithRetryAfter(http.StatusServiceUnavailable, "120"),
+		httpbreaker.WithRetryAfterHeader(),
+	)
+
+	resp, err := s.send(rt)
+
+	s.Require().NoError(err)
+	s.Equal("120", resp.Header.Get("Retry-After"))
+	s.Equal(2*time.Minute, s.openFor(c))
+	clock.Advance(time.Minute)
+	s.Equal(breaker.Open, c.State())
+	clock.Advance(time.Minute)
+	s.Equal(breaker.HalfOpen, c.State())
+}
+
+func (s *RoundTripperSuite) TestRetryAfterHeader_HTTPDate() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Second),
+	)
+	at := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	rt := httpbreaker.NewRoundTripper(c, respondWithRetryAfter(http.StatusServiceUnavailable, at),
+		httpbreaker.WithRetryAfterHeader(),
+	)
+
+	_, err := s.send(rt)
+
+	s.Require().NoError(err)
+	s.InDelta(float64(time.Hour), float64(s.openFor(c)), float64(2*time.Second))
+}
+
+func (s *RoundTripperSuite) TestRetryAfterHeader_FallsBackToOpenDuration() {
+	for name, value := range map[string]string{
+		"absent":   "",
+		"invalid":  "soon",
+		"negative": "-5",
+		"past":     time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat),
+	} {
+		s.Run(name, func() {
+			c := breaker.New("test",
+				breaker.WithFailureThreshold(1),
+				breaker.WithOpenDuration(time.Second),
+			)
+			rt := httpbreaker.NewRoundTripper(c, respondWithRetryAfter(http.StatusServiceUnavailable, value),
+				httpbreaker.WithRetryAfterHeader(),
+			)
+
+			_, err := s.send(rt)
+
+			s.Require().NoError(err)
+			s.Equal(time.Second, s.openFor(c))
+		})
+	}
+}
+
+func (s *RoundTripperSuite) TestRetryAfterHeader_IgnoredWithoutOption() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Second),
+	)
+
+	_, err := s.send(httpbreaker.NewRoundTripper(c, respondWithRetryAfter(http.StatusServiceUnavailable, "120")))
+
+	s.Require().NoError(err)
+	s.Equal(time.Second, s.openFor(c))
+}
+
+func TestNewHTTPClient_PreservesBaseSettings(t *testing.T) {
+	c := breaker.New("test")
+	redirect := func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	base := &http.Client{
+		Transport:     respondWith(http.StatusOK),
+		Timeout:       3 * time.Second,
+		CheckRedirect: redirect,
+	}
+
+	client := httpbreaker.NewHTTPClient(c, base)
+
+	require.Same(t, c, client.Circuit())
+	require.Equal(t, 3*time.Second, client.Timeout)
+	require.NotNil(t, client.CheckRedirect)
+	require.NotSame(t, base, client.Client)
+
+	resp, err := client.Get("http://example.com/")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	c.ForceOpen()
+	_, err = client.Get("http://example.com/")
+	require.True(t, breaker.IsOpen(err), "expected the transport to be wrapped")
+	_, unchanged := base.Transport.(roundTripFunc)
+	require.True(t, unchanged, "expected base to be left unchanged")
+}
+
+func TestNewHTTPClient_NilBaseUsesDefaultClient(t *testing.T) {
+	c := breaker.New("test", breaker.WithFailureThreshold(1))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	client := httpbreaker.NewHTTPClient(c, nil)
+
+	require.Equal(t, http.DefaultClient.Timeout, client.Timeout)
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, breaker.Open, c.State())
+
+	_, err = client.Get(srv.URL)
+	require.True(t, breaker.IsOpen(err))
+}
@@ -0,0 +1,77 @@
+package breaker
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// LatencyStats summarizes the durations of recent calls.
+type LatencyStats struct {
+	// Count is the number of calls the percentiles are computed over.
+	Count int
+
+	P50 time.Duration
+	P90 time.Duration
+	P99 time.Duration
+}
+
+// latencyRing holds the most recent call durations. It has its own lock so
+// recording a duration does not contend with state changes.
+type latencyRing struct {
+	mu   sync.Mutex
+	buf  []time.Duration
+	next int
+	full bool
+}
+
+func newLatencyRing(size int) *latencyRing {
+	return &latencyRing{buf: make([]time.Duration, size)}
+}
+
+func (r *latencyRing) add(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buf[r.next] = d
+	r.next++
+	if r.next == len(r.buf) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+func (r *latencyRing) samples() []time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.full {
+		return slices.Clone(r.buf)
+	}
+	return slices.Clone(r.buf[:r.next])
+}
+
+// Latency returns percentiles of the durations of the most recent calls
+// executed through the circuit, as configured by WithLatencyWindow. It
+// returns zero stats if WithLatencyWindow is not set or no calls have
+// completed. Rejected calls are not included.
+func (c *Circuit) Latency() LatencyStats {
+	if c.latency == nil {
+		return LatencyStats{}
+	}
+	samples := c.latency.samples()
+	if len(samples) == 0 {
+		return LatencyStats{}
+	}
+	slices.Sort(samples)
+	return LatencyStats{
+		Count: len(samples),
+		P50:   percentile(samples, 50),
+		P90:   percentile(samples, 90),
+		P99:   percentile(samples, 99),
+	}
+}
+
+// percentile returns the nearest-rank pth percentile of sorted.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank-1, 0)]
+}
@@ -0,0 +1,89 @@
+package breaker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bjaus/breaker"
+	"github.com/stretchr/testify/suite"
+)
+
+type LatencySuite struct {
+	suite.Suite
+	clock *fakeClock
+}
+
+func TestLatencySuite(t *testing.T) {
+	suite.Run(t, new(LatencySuite))
+}
+
+func (s *LatencySuite) SetupTest() {
+	s.clock = newFakeClock()
+}
+
+// call makes a call through c that takes d on the fake clock.
+func (s *LatencySuite) call(c *breaker.Circuit, d time.Duration) {
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		s.clock.Advance(d)
+		return nil
+	})
+}
+
+func (s *LatencySuite) TestLatency_ComputesPercentiles() {
+	c := breaker.New("test",
+		breaker.WithLatencyWindow(100),
+		breaker.WithClock(s.clock),
+	)
+
+	for i := 1; i <= 100; i++ {
+		s.call(c, time.Duration(i)*time.Millisecond)
+	}
+
+	s.Equal(breaker.LatencyStats{
+		Count: 100,
+		P50:   50 * time.Millisecond,
+		P90:   90 * time.Millisecond,
+		P99:   99 * time.Millisecond,
+	}, c.Latency())
+}
+
+func (s *LatencySuite) TestLatency_KeepsOnlyRecentCalls() {
+	c := breaker.New("test",
+		breaker.WithLatencyWindow(3),
+		breaker.WithClock(s.clock),
+	)
+
+	s.call(c, time.Hour)
+	for range 3 {
+		s.call(c, time.Millisecond)
+	}
+
+	stats := c.Latency()
+	s.Equal(3, stats.Count)
+	s.Equal(time.Millisecond, stats.P99)
+}
+
+func (s *LatencySuite) TestLatency_PartiallyFilledWindow() {
+	c := breaker.New("test",
+		breaker.WithLatencyWindow(10),
+		breaker.WithClock(s.clock),
+	)
+
+	s.call(c, time.Second)
+
+	s.Equal(breaker.LatencyStats{
+		Count: 1,
+		P50:   time.Second,
+		P90:   time.Second,
+		P99:   time.Second,
+	}, c.Latency())
+}
+
+func (s *LatencySuite) TestLatency_ZeroWithoutWindow() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+
+	s.call(c, time.Second)
+
+	s.Equal(breaker.LatencyStats{}, c.Latency())
+}
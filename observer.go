@@ -0,0 +1,273 @@
+package breaker
+
+import (
+	"maps"
+	"time"
+)
+
+// EventKind identifies the type of a CircuitEvent.
+type EventKind int
+
+const (
+	// EventCall is emitted after each call attempt.
+	EventCall EventKind = iota
+
+	// EventStateChange is emitted when the circuit changes state.
+	EventStateChange
+
+	// EventReject is emitted when a call is rejected due to open circuit.
+	EventReject
+
+	// EventAutoReset is emitted when WithAutoReset closes a stuck circuit,
+	// after the corresponding EventStateChange.
+	EventAutoReset
+
+	// EventRecover is emitted when trial calls close a half-open circuit,
+	// after the corresponding EventStateChange. Reset and WithAutoReset do
+	// not emit it.
+	EventRecover
+
+	// EventProbe is emitted when a call made while the circuit is
+	// half-open is evaluated, before any state change it causes.
+	EventProbe
+)
+
+// String returns the string representation of the event kind.
+func (k EventKind) String() string {
+	switch k {
+	case EventCall:
+		return "call"
+	case EventStateChange:
+		return "state-change"
+	case EventReject:
+		return "reject"
+	case EventAutoReset:
+		return "auto-reset"
+	case EventRecover:
+		return "recover"
+	case EventProbe:
+		return "probe"
+	default:
+		return "unknown"
+	}
+}
+
+// RejectReason identifies why a call was rejected.
+type RejectReason int
+
+const (
+	// ReasonOpen means the circuit is open.
+	ReasonOpen RejectReason = iota
+
+	// ReasonHalfOpenBudget means the circuit is half-open and its trial
+	// calls are used up, as limited by WithHalfOpenRequests,
+	// WithHalfOpenRatio, or WithProbeInterval.
+	ReasonHalfOpenBudget
+
+	// ReasonOverloaded means the circuit is closed but WithRecoveryRamp
+	// is capping concurrent calls.
+	ReasonOverloaded
+)
+
+// String returns the string representation of the reason.
+func (r RejectReason) String() string {
+	switch r {
+	case ReasonOpen:
+		return "open"
+	case ReasonHalfOpenBudget:
+		return "half-open-budget"
+	case ReasonOverloaded:
+		return "overloaded"
+	default:
+		return "unknown"
+	}
+}
+
+// rejectReason returns why allow rejected a call in state. Each state
+// rejects for only one reason.
+func rejectReason(state State) RejectReason {
+	switch state {
+	case HalfOpen:
+		return ReasonHalfOpenBudget
+	case Closed:
+		return ReasonOverloaded
+	default:
+		return ReasonOpen
+	}
+}
+
+// CircuitEvent describes something that happened to a circuit. Which
+// fields are set depends on Kind:
+//
+//   - EventCall: State, Err, and Duration
+//   - EventStateChange: From and To
+//   - EventReject: State and Reason
+//   - EventAutoReset: no additional fields
+//   - EventRecover: Duration, the time spent not Closed
+//   - EventProbe: State, Err, and Success
+type CircuitEvent struct {
+	Kind EventKind
+	Name string
+	At   time.Time
+
+	// ID is the circuit's ID.
+	ID string
+
+	// Tags is a copy of the circuit's WithTags metadata, or nil if it has
+	// none.
+	Tags map[string]string
+
+	// State is the state the circuit was in when the call was admitted
+	// or rejected.
+	State State
+
+	From State
+	To   State
+
+	Reason RejectReason
+
+	Err      error
+	Duration time.Duration
+
+	// Success reports whether a half-open trial succeeded.
+	Success bool
+}
+
+// Outcome is an audit record of a single executed call.
+type Outcome struct {
+	// At is when the call completed, per the circuit's clock.
+	At time.Time
+
+	Name string
+
+	// State is the state the circuit was in when the call was admitted.
+	State State
+
+	Err      error
+	Duration time.Duration
+}
+
+// Observer receives circuit events. Implementations must be safe for
+// concurrent use. State change events are delivered while the circuit's
+// lock is held, so Observe must not call back into the circuit.
+type Observer interface {
+	Observe(CircuitEvent)
+}
+
+// ObserverFunc adapts a function to the Observer interface.
+type ObserverFunc func(CircuitEvent)
+
+// Observe calls f(e).
+func (f ObserverFunc) Observe(e CircuitEvent) {
+	f(e)
+}
+
+// eventQueue publishes events to a WithEventBuffer channel, dropping the
+// oldest queued event to make room when the channel is full.
+type eventQueue chan CircuitEvent
+
+func (q eventQueue) Observe(e CircuitEvent) {
+	for {
+		select {
+		case q <- e:
+			return
+		default:
+		}
+		select {
+		case <-q:
+		default:
+		}
+	}
+}
+
+// Events returns the channel of events queued by WithEventBuffer, or nil
+// if the circuit has no event buffer. The channel is never closed, so a
+// consumer should stop on its own signal rather than ranging to the end.
+func (c *Circuit) Events() <-chan CircuitEvent {
+	return c.events
+}
+
+// MultiObserver returns an Observer that dispatches every event to each of
+// observers in order.
+func MultiObserver(observers ...Observer) Observer {
+	return multiObserver(observers)
+}
+
+type multiObserver []Observer
+
+func (m multiObserver) Observe(e CircuitEvent) {
+	for _, o := range m {
+		o.Observe(e)
+	}
+}
+
+// hookObserver adapts the OnCall, OnCallTagged, OnStateChange, OnReject,
+// OnRejectReason, OnAutoReset, OnRecover, and OnProbe hook functions to
+// Observer.
+// Nil hooks are skipped.
+type hookObserver struct {
+	onCall         OnCallFunc
+	onCallTagged   OnCallTaggedFunc
+	onStateChange  OnStateChangeFunc
+	onReject       OnRejectFunc
+	onRejectReason OnRejectReasonFunc
+	onAutoReset    OnAutoResetFunc
+	onRecover      OnRecoverFunc
+	onProbe        OnProbeFunc
+}
+
+func (h hookObserver) Observe(e CircuitEvent) {
+	switch e.Kind {
+	case EventCall:
+		if h.onCall != nil {
+			h.onCall(e.Name, e.State, e.Err)
+		}
+		if h.onCallTagged != nil {
+			h.onCallTagged(e.Name, e.Tags, e.State, e.Err)
+		}
+	case EventStateChange:
+		if h.onStateChange != nil {
+			h.onStateChange(e.Name, e.From, e.To)
+		}
+	case EventReject:
+		if h.onReject != nil {
+			h.onReject(e.Name)
+		}
+		if h.onRejectReason != nil {
+			h.onRejectReason(e.Name, e.Reason)
+		}
+	case EventAutoReset:
+		if h.onAutoReset != nil {
+			h.onAutoReset(e.Name)
+		}
+	case EventRecover:
+		if h.onRecover != nil {
+			h.onRecover(e.Name, e.Duration)
+		}
+	case EventProbe:
+		if h.onProbe != nil {
+			h.onProbe(e.Name, e.Success)
+		}
+	}
+}
+
+// emit dispatches e to the circuit's observers. Each observer gets its
+// own copy of the tags so none can change what the others see.
+func (c *Circuit) emit(e CircuitEvent) {
+	e.ID = c.id
+	for _, o := range c.cfg.observers {
+		e.Tags = maps.Clone(c.cfg.tags)
+		o.Observe(e)
+	}
+	// State changes are emitted with mu held, which guards broadcast.
+	if e.Kind == EventStateChange && c.broadcast != nil {
+		e.Tags = c.cfg.tags
+		c.broadcast.publish(e)
+	}
+}
+
+// Tags returns a copy of the circuit's WithTags metadata, or nil if it has
+// none.
+func (c *Circuit) Tags() map[string]string {
+	return maps.Clone(c.cfg.tags)
+}
@@ -0,0 +1,556 @@
+package breaker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bjaus/breaker"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// recordingObserver collects every event it receives.
+type recordingObserver struct {
+	events []breaker.CircuitEvent
+}
+
+func (r *recordingObserver) Observe(e breaker.CircuitEvent) {
+	r.events = append(r.events, e)
+}
+
+func (r *recordingObserver) kinds() []breaker.EventKind {
+	kinds := make([]breaker.EventKind, len(r.events))
+	for i, e := range r.events {
+		kinds[i] = e.Kind
+	}
+	return kinds
+}
+
+type ObserverSuite struct {
+	suite.Suite
+	clock *fakeClock
+}
+
+func TestObserverSuite(t *testing.T) {
+	suite.Run(t, new(ObserverSuite))
+}
+
+func (s *ObserverSuite) SetupTest() {
+	s.clock = newFakeClock()
+}
+
+func (s *ObserverSuite) TestWithObserver_ReceivesAllEvents() {
+	obs := &recordingObserver{}
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithObserver(obs),
+		breaker.WithClock(s.clock),
+	)
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		s.clock.Advance(250 * time.Millisecond)
+		return errTest
+	}), errTest)
+	s.True(breaker.IsOpen(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	})))
+
+	s.Equal([]breaker.EventKind{breaker.EventStateChange, breaker.EventCall, breaker.EventReject}, obs.kinds())
+
+	change := obs.events[0]
+	s.Equal("test", change.Name)
+	s.Equal(breaker.Closed, change.From)
+	s.Equal(breaker.Open, change.To)
+	s.Equal(s.clock.Now(), change.At)
+
+	call := obs.events[1]
+	s.Equal(breaker.Closed, call.State)
+	s.ErrorIs(call.Err, errTest)
+	s.Equal(250*time.Millisecond, call.Duration)
+
+	reject := obs.events[2]
+	s.Equal(breaker.Open, reject.State)
+}
+
+func (s *ObserverSuite) TestMultiObserver_DispatchesToEach() {
+	a, b := &recordingObserver{}, &recordingObserver{}
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithObserver(breaker.MultiObserver(a, b)),
+		breaker.WithClock(s.clock),
+	)
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	})
+
+	s.Len(a.events, 3)
+	s.Equal(a.events, b.events)
+}
+
+func (s *ObserverSuite) TestObserverFunc() {
+	var kinds []breaker.EventKind
+	c := breaker.New("test",
+		breaker.WithObserver(breaker.ObserverFunc(func(e breaker.CircuitEvent) {
+			kinds = append(kinds, e.Kind)
+		})),
+		breaker.WithClock(s.clock),
+	)
+
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+
+	s.Equal([]breaker.EventKind{breaker.EventCall}, kinds)
+}
+
+func (s *ObserverSuite) TestHooksAndObserversInteroperate() {
+	obs := &recordingObserver{}
+	var hookCalls, secondHookCalls int
+	c := breaker.New("test",
+		breaker.WithObserver(obs),
+		breaker.OnCall(func(name string, state breaker.State, err error) {
+			hookCalls++
+		}),
+		breaker.OnCall(func(name string, state breaker.State, err error) {
+			secondHookCalls++
+		}),
+		breaker.WithClock(s.clock),
+	)
+
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+
+	s.Len(obs.events, 1)
+	s.Equal(1, hookCalls)
+	s.Equal(1, secondHookCalls, "expected repeated hook options to accumulate")
+}
+
+func (s *ObserverSuite) TestOutcomeSink_RecordsEveryExecutedCall() {
+	var outcomes []breaker.Outcome
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+		breaker.WithOutcomeSink(func(o breaker.Outcome) {
+			outcomes = append(outcomes, o)
+		}),
+	)
+
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		s.clock.Advance(10 * time.Millisecond)
+		return nil
+	}))
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		s.clock.Advance(20 * time.Millisecond)
+		return errTest
+	}), errTest)
+	s.True(breaker.IsOpen(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	})))
+
+	s.Require().Len(outcomes, 2, "expected rejected calls to be skipped")
+
+	s.Equal("test", outcomes[0].Name)
+	s.Equal(breaker.Closed, outcomes[0].State)
+	s.NoError(outcomes[0].Err)
+	s.Equal(10*time.Millisecond, outcomes[0].Duration)
+
+	s.ErrorIs(outcomes[1].Err, errTest)
+	s.Equal(20*time.Millisecond, outcomes[1].Duration)
+	s.Equal(s.clock.Now(), outcomes[1].At)
+}
+
+func (s *ObserverSuite) TestWithTags_PassedToObserversAndTaggedHooks() {
+	tags := map[string]string{"team": "billing"}
+	obs := &recordingObserver{}
+	var hookTags []map[string]string
+	c := breaker.New("payment-service",
+		breaker.WithTags(tags),
+		breaker.WithTags(map[string]string{"tier": "critical"}),
+		breaker.WithObserver(obs),
+		breaker.OnCallTagged(func(name string, tags map[string]string, state breaker.State, err error) {
+			hookTags = append(hookTags, tags)
+		}),
+		breaker.WithClock(s.clock),
+	)
+	tags["team"] = "changed"
+
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+
+	want := map[string]string{"team": "billing", "tier": "critical"}
+	s.Require().Len(obs.events, 1)
+	s.Equal(want, obs.events[0].Tags)
+	s.Equal([]map[string]string{want}, hookTags)
+}
+
+func (s *ObserverSuite) TestWithSamplingRate_SamplesOnlyCallEvents() {
+	rolls := []float64{0.9, 0.1, 0.5, 0.2}
+	var calls, rejects int
+	obs := &recordingObserver{}
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(4),
+		breaker.WithSamplingRate(0.25),
+		breaker.WithRand(func() float64 {
+			r := rolls[0]
+			rolls = rolls[1:]
+			return r
+		}),
+		breaker.WithObserver(obs),
+		breaker.OnCall(func(name string, state breaker.State, err error) {
+			calls++
+		}),
+		breaker.OnReject(func(name string) {
+			rejects++
+		}),
+		breaker.WithClock(s.clock),
+	)
+
+	for range 4 {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		})
+	}
+	s.True(breaker.IsOpen(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	})))
+
+	s.Equal(2, calls)
+	s.Equal(1, rejects)
+	s.Equal([]breaker.EventKind{
+		breaker.EventCall,
+		breaker.EventStateChange,
+		breaker.EventCall,
+		breaker.EventReject,
+	}, obs.kinds())
+	s.InDelta(0.25, c.Snapshot().SamplingRate, 0)
+}
+
+func (s *ObserverSuite) TestWithSamplingRate_DefaultReportsEveryCall() {
+	var calls int
+	c := breaker.New("test",
+		breaker.WithRand(func() float64 {
+			s.Fail("rand should not be consulted")
+			return 0
+		}),
+		breaker.OnCall(func(name string, state breaker.State, err error) {
+			calls++
+		}),
+		breaker.WithClock(s.clock),
+	)
+
+	for range 3 {
+		s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+			return nil
+		}))
+	}
+
+	s.Equal(3, calls)
+	s.InDelta(1.0, c.Snapshot().SamplingRate, 0)
+}
+
+func (s *ObserverSuite) TestWithSamplingRate_ZeroReportsNoCalls() {
+	var calls, rejects int
+	c, err := breaker.NewWithError("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithSamplingRate(0),
+		breaker.OnCall(func(name string, state breaker.State, err error) {
+			calls++
+		}),
+		breaker.OnReject(func(name string) {
+			rejects++
+		}),
+		breaker.WithClock(s.clock),
+	)
+	s.Require().NoError(err)
+
+	for range 10 {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		})
+	}
+
+	s.Zero(calls)
+	s.Equal(9, rejects)
+	s.Zero(c.Snapshot().SamplingRate)
+}
+
+func (s *ObserverSuite) TestWithSamplingRate_ClampsOutOfRange() {
+	tests := map[string]struct {
+		rate float64
+		want float64
+	}{
+		"negative":  {rate: -0.5, want: 0},
+		"above one": {rate: 1.5, want: 1},
+	}
+
+	for name, tc := range tests {
+		s.Run(name, func() {
+			c := breaker.New("test", breaker.WithSamplingRate(tc.rate))
+
+			s.InDelta(tc.want, c.Snapshot().SamplingRate, 0)
+		})
+	}
+}
+
+func (s *ObserverSuite) TestWithTags_CannotBeMutatedThroughReaders() {
+	c := breaker.New("test",
+		breaker.WithTags(map[string]string{"team": "billing"}),
+		breaker.OnCallTagged(func(name string, tags map[string]string, state breaker.State, err error) {
+			tags["team"] = "hook"
+		}),
+		breaker.WithClock(s.clock),
+	)
+
+	c.Tags()["team"] = "accessor"
+	c.Snapshot().Tags["team"] = "snapshot"
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+
+	s.Equal(map[string]string{"team": "billing"}, c.Tags())
+}
+
+func (s *ObserverSuite) TestWithTags_NilWithoutTags() {
+	obs := &recordingObserver{}
+	c := breaker.New("test", breaker.WithObserver(obs), breaker.WithClock(s.clock))
+
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+
+	s.Nil(c.Tags())
+	s.Nil(c.Snapshot().Tags)
+	s.Require().Len(obs.events, 1)
+	s.Nil(obs.events[0].Tags)
+}
+
+func (s *ObserverSuite) TestOnRejectReason_OpenAndHalfOpenBudget() {
+	var reasons []breaker.RejectReason
+	rejects := 0
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithClock(s.clock),
+		breaker.OnReject(func(name string) {
+			rejects++
+		}),
+		breaker.OnRejectReason(func(name string, reason breaker.RejectReason) {
+			reasons = append(reasons, reason)
+		}),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	})
+	s.clock.Advance(time.Minute)
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		s.True(breaker.IsOpen(c.Do(ctx, func(ctx context.Context) error {
+			return nil
+		})))
+		return nil
+	})
+
+	s.Equal([]breaker.RejectReason{breaker.ReasonOpen, breaker.ReasonHalfOpenBudget}, reasons)
+	s.Equal(2, rejects, "expected OnReject to keep firing alongside OnRejectReason")
+}
+
+func (s *ObserverSuite) TestOnRejectReason_OverloadedDuringRecoveryRamp() {
+	var reasons []breaker.RejectReason
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithSuccessThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithRecoveryRamp(10*time.Second),
+		breaker.WithClock(s.clock),
+		breaker.OnRejectReason(func(name string, reason breaker.RejectReason) {
+			reasons = append(reasons, reason)
+		}),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(time.Minute)
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		_ = c.Do(ctx, func(ctx context.Context) error {
+			return nil
+		})
+		return nil
+	}))
+
+	s.Equal([]breaker.RejectReason{breaker.ReasonOverloaded}, reasons)
+}
+
+func (s *ObserverSuite) TestOnRejectReason_FailedProbeReportsOpen() {
+	var reasons []breaker.RejectReason
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithProbe(func(ctx context.Context) error {
+			return errTest
+		}),
+		breaker.WithClock(s.clock),
+		breaker.OnRejectReason(func(name string, reason breaker.RejectReason) {
+			reasons = append(reasons, reason)
+		}),
+	)
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(time.Minute)
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	})
+
+	s.Equal([]breaker.RejectReason{breaker.ReasonOpen}, reasons)
+}
+
+func (s *ObserverSuite) TestWithEventBuffer_QueuesAllEvents() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithEventBuffer(8),
+		breaker.WithClock(s.clock),
+	)
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+	s.True(breaker.IsOpen(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	})))
+
+	var kinds []breaker.EventKind
+	for range 3 {
+		e := <-c.Events()
+		s.Equal("test", e.Name)
+		kinds = append(kinds, e.Kind)
+	}
+	s.Equal([]breaker.EventKind{breaker.EventStateChange, breaker.EventCall, breaker.EventReject}, kinds)
+	s.Empty(c.Events())
+}
+
+func (s *ObserverSuite) TestWithEventBuffer_DropsOldestWhenFull() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(10),
+		breaker.WithEventBuffer(2),
+		breaker.WithClock(s.clock),
+	)
+
+	for i := range 5 {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			s.clock.Advance(time.Duration(i) * time.Second)
+			return nil
+		})
+	}
+
+	s.Len(c.Events(), 2)
+	s.Equal(3*time.Second, (<-c.Events()).Duration)
+	s.Equal(4*time.Second, (<-c.Events()).Duration)
+}
+
+func (s *ObserverSuite) TestWithEventBuffer_QueuesAfterObservers() {
+	obs := &recordingObserver{}
+	c := breaker.New("test",
+		breaker.WithEventBuffer(1),
+		breaker.WithObserver(obs),
+		breaker.WithClock(s.clock),
+	)
+
+	s.NoError(c.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+
+	s.Equal([]breaker.EventKind{breaker.EventCall}, obs.kinds())
+	s.Equal(breaker.EventCall, (<-c.Events()).Kind)
+}
+
+func (s *ObserverSuite) TestEvents_NilWithoutBuffer() {
+	s.Nil(breaker.New("test").Events())
+}
+
+func TestRejectReason_String(t *testing.T) {
+	tests := map[string]struct {
+		reason breaker.RejectReason
+		want   string
+	}{
+		"open":             {reason: breaker.ReasonOpen, want: "open"},
+		"half-open budget": {reason: breaker.ReasonHalfOpenBudget, want: "half-open-budget"},
+		"overloaded":       {reason: breaker.ReasonOverloaded, want: "overloaded"},
+		"unknown":          {reason: breaker.RejectReason(99), want: "unknown"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.reason.String())
+		})
+	}
+}
+
+func TestEventKind_String(t *testing.T) {
+	tests := map[string]struct {
+		kind breaker.EventKind
+		want string
+	}{
+		"call":         {kind: breaker.EventCall, want: "call"},
+		"state change": {kind: breaker.EventStateChange, want: "state-change"},
+		"reject":       {kind: breaker.EventReject, want: "reject"},
+		"auto reset":   {kind: breaker.EventAutoReset, want: "auto-reset"},
+		"recover":      {kind: breaker.EventRecover, want: "recover"},
+		"probe":        {kind: breaker.EventProbe, want: "probe"},
+		"unknown":      {kind: breaker.EventKind(99), want: "unknown"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.kind.String())
+		})
+	}
+}
+
+func TestNameTags(t *testing.T) {
+	tests := map[string]struct {
+		name string
+		keys []string
+		want map[string]string
+	}{
+		"one part per key": {
+			name: "payments/stripe/charge",
+			keys: []string{"service", "provider", "op"},
+			want: map[string]string{"service": "payments", "provider": "stripe", "op": "charge"},
+		},
+		"last key takes the rest": {
+			name: "payments/stripe/charge",
+			keys: []string{"service", "rest"},
+			want: map[string]string{"service": "payments", "rest": "stripe/charge"},
+		},
+		"fewer parts than keys": {
+			name: "payments",
+			keys: []string{"service", "provider"},
+			want: map[string]string{"service": "payments"},
+		},
+		"no keys": {
+			name: "payments/stripe",
+			want: map[string]string{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, breaker.NameTags(tc.name, tc.keys...))
+		})
+	}
+}
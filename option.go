@@ -1,31 +1,236 @@
 package breaker
 
-import "time"
+import (
+	"errors"
+	"maps"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
 
 type config struct {
 	failureThreshold int
+	thresholdFunc    func() int
 	successThreshold int
 	openDuration     time.Duration
+	window           time.Duration
 	halfOpenRequests int
+	halfOpenRatio    float64
+	samplingRate     float64
+	halfOpenInterval time.Duration
+	halfOpenTimeout  time.Duration
+	maxHalfOpen      time.Duration
+	recoveryRamp     time.Duration
+	cooldown         time.Duration
+	minClosed        time.Duration
+	callLimit        int
+	latencyWindow    int
+	history          int
+	eventBuffer      int
+	velocityN        int
+	velocityWindow   time.Duration
+	warmup           time.Duration
+	warmupCalls      int
+	disabled         bool
+	initialState     State
+	initialOpenedAt  time.Time
+	drainTimeout     time.Duration
+	autoReset        time.Duration
+	probeInterval    time.Duration
+	probe            Func
+	halfOpenProbe    Func
+	probeFunc        Func
 	condition        Condition
+	tripOn           Condition
+	failureWeight    func(error) int
+	contextCondition ContextCondition
+	countCanceled    bool
+	budget           *Budget
+	batchPolicy      BatchPolicy
+	dryRun           bool
+	rwMutex          bool
+	reentrancyCheck  bool
+	richErrors       bool
+	fairHalfOpen     bool
+	priorityQueue    bool
+	recoverPanics    bool
+	repanic          bool
 	clock            Clock
+	rand             func() float64
+	tags             map[string]string
+	namespace        string
+	id               string
+	maxListeners     int
 
-	onStateChange OnStateChangeFunc
-	onCall        OnCallFunc
-	onReject      OnRejectFunc
+	observers   []Observer
+	outcomeSink func(Outcome)
 }
 
 // Option configures a Circuit.
 type Option func(*config)
 
+func newConfig(opts []Option) config {
+	cfg := config{
+		failureThreshold: DefaultFailureThreshold,
+		successThreshold: DefaultSuccessThreshold,
+		openDuration:     DefaultOpenDuration,
+		halfOpenRequests: DefaultHalfOpenRequests,
+		samplingRate:     1,
+		clock:            DefaultClock(),
+		rand:             rand.Float64,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// normalize replaces invalid settings with their defaults.
+func (c *config) normalize() {
+	if c.failureThreshold < 1 {
+		c.failureThreshold = DefaultFailureThreshold
+	}
+	if c.successThreshold < 1 {
+		c.successThreshold = DefaultSuccessThreshold
+	}
+	if c.openDuration < 0 {
+		c.openDuration = DefaultOpenDuration
+	}
+	if c.halfOpenRequests < 1 {
+		c.halfOpenRequests = DefaultHalfOpenRequests
+	}
+	c.halfOpenRatio = min(max(c.halfOpenRatio, 0), 1)
+	c.samplingRate = min(max(c.samplingRate, 0), 1)
+	c.halfOpenInterval = max(c.halfOpenInterval, 0)
+	c.halfOpenTimeout = max(c.halfOpenTimeout, 0)
+	c.maxHalfOpen = max(c.maxHalfOpen, 0)
+	c.recoveryRamp = max(c.recoveryRamp, 0)
+	c.cooldown = max(c.cooldown, 0)
+	c.minClosed = max(c.minClosed, 0)
+	c.callLimit = max(c.callLimit, 0)
+	c.latencyWindow = max(c.latencyWindow, 0)
+	c.history = max(c.history, 0)
+	c.eventBuffer = max(c.eventBuffer, 0)
+	c.maxListeners = max(c.maxListeners, 0)
+	if c.velocityN < 1 || c.velocityWindow <= 0 {
+		c.velocityN, c.velocityWindow = 0, 0
+	}
+	c.window = max(c.window, 0)
+	c.warmup = max(c.warmup, 0)
+	c.warmupCalls = max(c.warmupCalls, 0)
+	c.drainTimeout = max(c.drainTimeout, 0)
+	c.autoReset = max(c.autoReset, 0)
+	if c.clock == nil {
+		c.clock = realClock{}
+	}
+	if c.rand == nil {
+		c.rand = rand.Float64
+	}
+	switch c.initialState {
+	case Open:
+		now := c.clock.Now()
+		switch {
+		case c.initialOpenedAt.IsZero():
+			c.initialOpenedAt = now
+		case c.initialOpenedAt.After(now):
+			c.initialState = Closed
+			c.initialOpenedAt = time.Time{}
+		}
+	case HalfOpen:
+		c.initialOpenedAt = time.Time{}
+	default:
+		c.initialState = Closed
+		c.initialOpenedAt = time.Time{}
+	}
+}
+
+// validate reports the first invalid setting in cfg as a *ConfigError.
+func (c config) validate() error {
+	switch {
+	case c.failureThreshold < 1:
+		return &ConfigError{Field: "FailureThreshold", Message: "must be at least 1"}
+	case c.successThreshold < 1:
+		return &ConfigError{Field: "SuccessThreshold", Message: "must be at least 1"}
+	case c.openDuration < 0:
+		return &ConfigError{Field: "OpenDuration", Message: "must not be negative"}
+	case c.window < 0:
+		return &ConfigError{Field: "Window", Message: "must not be negative"}
+	case c.halfOpenRequests < 1:
+		return &ConfigError{Field: "HalfOpenRequests", Message: "must be at least 1"}
+	case c.halfOpenRatio < 0 || c.halfOpenRatio > 1:
+		return &ConfigError{Field: "HalfOpenRatio", Message: "must be between 0 and 1"}
+	case c.samplingRate < 0 || c.samplingRate > 1:
+		return &ConfigError{Field: "SamplingRate", Message: "must be between 0 and 1"}
+	case c.halfOpenInterval < 0:
+		return &ConfigError{Field: "ProbeInterval", Message: "must not be negative"}
+	case c.halfOpenTimeout < 0:
+		return &ConfigError{Field: "HalfOpenProbeTimeout", Message: "must not be negative"}
+	case c.maxHalfOpen < 0:
+		return &ConfigError{Field: "MaxHalfOpenDuration", Message: "must not be negative"}
+	case c.velocityN < 0 || c.velocityWindow < 0:
+		return &ConfigError{Field: "VelocityThreshold", Message: "must not be negative"}
+	case c.velocityN > 0 && c.velocityWindow == 0:
+		return &ConfigError{Field: "VelocityThreshold", Message: "window must be positive"}
+	case c.latencyWindow < 0:
+		return &ConfigError{Field: "LatencyWindow", Message: "must not be negative"}
+	case c.history < 0:
+		return &ConfigError{Field: "History", Message: "must not be negative"}
+	case c.eventBuffer < 0:
+		return &ConfigError{Field: "EventBuffer", Message: "must not be negative"}
+	case c.maxListeners < 0:
+		return &ConfigError{Field: "MaxStateChangeListeners", Message: "must not be negative"}
+	case c.callLimit < 0:
+		return &ConfigError{Field: "CallLimit", Message: "must not be negative"}
+	case c.cooldown < 0:
+		return &ConfigError{Field: "Cooldown", Message: "must not be negative"}
+	case c.minClosed < 0:
+		return &ConfigError{Field: "MinClosedDuration", Message: "must not be negative"}
+	case c.recoveryRamp < 0:
+		return &ConfigError{Field: "RecoveryRamp", Message: "must not be negative"}
+	case c.warmup < 0:
+		return &ConfigError{Field: "Warmup", Message: "must not be negative"}
+	case c.warmupCalls < 0:
+		return &ConfigError{Field: "WarmupCalls", Message: "must not be negative"}
+	case c.drainTimeout < 0:
+		return &ConfigError{Field: "GracefulDrain", Message: "timeout must not be negative"}
+	case c.autoReset < 0:
+		return &ConfigError{Field: "AutoReset", Message: "interval must not be negative"}
+	case c.probe != nil && c.probeInterval <= 0:
+		return &ConfigError{Field: "ActiveProbe", Message: "interval must be positive"}
+	case c.clock == nil:
+		return &ConfigError{Field: "Clock", Message: "must not be nil"}
+	case c.rand == nil:
+		return &ConfigError{Field: "Rand", Message: "must not be nil"}
+	case c.initialState != Closed && c.initialState != Open && c.initialState != HalfOpen:
+		return &ConfigError{Field: "InitialState", Message: "must be closed, open, or half-open"}
+	case c.initialState == Open && c.initialOpenedAt.After(c.clock.Now()):
+		return &ConfigError{Field: "InitialState", Message: "opened-at must not be in the future"}
+	}
+	return nil
+}
+
 // WithFailureThreshold sets consecutive failures before opening the circuit.
-// Default is 5.
+// Default is 5. Values below 1 are invalid: New uses the default instead,
+// so a zero from an unset config value never opens on the first failure,
+// while NewWithError and MustNew report a *ConfigError.
 func WithFailureThreshold(n int) Option {
 	return func(c *config) {
 		c.failureThreshold = n
 	}
 }
 
+// WithFailureThresholdFunc makes the failure threshold dynamic: fn is
+// called on each failure, under the circuit's lock, and its result is
+// used in place of WithFailureThreshold. This lets an external loop tune
+// the threshold, for example raising it at peak traffic, without
+// recreating the circuit. fn must be cheap and must not call back into the
+// circuit. Results below 1 fall back to the static threshold.
+func WithFailureThresholdFunc(fn func() int) Option {
+	return func(c *config) {
+		c.thresholdFunc = fn
+	}
+}
+
 // WithSuccessThreshold sets consecutive successes in half-open state
 // required before closing the circuit. Default is 2.
 func WithSuccessThreshold(n int) Option {
@@ -42,6 +247,63 @@ func WithOpenDuration(d time.Duration) Option {
 	}
 }
 
+// WithCallLimit opens the circuit once n calls have been admitted since it
+// last closed, whether they succeed or fail, for downstreams with a hard
+// quota. The nth call still runs. Later calls are rejected with an
+// *OpenError whose cause is ErrCallLimitExceeded until the circuit
+// recovers as usual. Default is 0 (no limit).
+func WithCallLimit(n int) Option {
+	return func(c *config) {
+		c.callLimit = n
+	}
+}
+
+// WithMinClosedDuration dampens a circuit that flaps between Closed and
+// Open. For d after the circuit closes, whether by recovery, Reset, or
+// WithAutoReset, failures are counted but reaching the failure threshold
+// does not reopen it; the first failure after d does if the count is still
+// at the threshold. A hard burst still opens it at once: a
+// WithVelocityThreshold spike or an exhausted shared Budget. Default is 0
+// (no minimum).
+func WithMinClosedDuration(d time.Duration) Option {
+	return func(c *config) {
+		c.minClosed = d
+	}
+}
+
+// WithCooldown slows down a circuit that flaps between Open and HalfOpen.
+// When a half-open trial call fails and the circuit reopens, that Open
+// period lasts the open duration plus d. If the next trial also fails
+// within d of entering half-open, the period grows by another d, and so
+// on. Closing resets the extension. Default is 0 (no cooldown).
+func WithCooldown(d time.Duration) Option {
+	return func(c *config) {
+		c.cooldown = d
+	}
+}
+
+// WithVelocityThreshold opens the circuit when n failures occur within any
+// rolling window, catching sudden spikes that successes in between would
+// hide from the consecutive failure threshold. The two thresholds apply
+// independently; whichever is reached first opens the circuit. Only
+// failures while Closed are tracked, and the history is cleared on every
+// state change. Default is 0 (no velocity threshold).
+func WithVelocityThreshold(n int, window time.Duration) Option {
+	return func(c *config) {
+		c.velocityN = n
+		c.velocityWindow = window
+	}
+}
+
+// WithWindow discards stale failures: if more than d has passed since the
+// previous failure when a new one occurs, the consecutive failure count
+// starts over. Default is 0 (failures never expire).
+func WithWindow(d time.Duration) Option {
+	return func(c *config) {
+		c.window = d
+	}
+}
+
 // WithHalfOpenRequests sets how many requests are allowed through
 // in the half-open state. Default is 1.
 func WithHalfOpenRequests(n int) Option {
@@ -50,14 +312,312 @@ func WithHalfOpenRequests(n int) Option {
 	}
 }
 
+// WithHalfOpenRatio admits roughly fraction (between 0 and 1) of calls
+// while the circuit is half-open and rejects the rest with ErrOpen, to ramp
+// traffic gently during recovery. Admitted calls still count against
+// WithHalfOpenRequests, so raise that limit accordingly. The circuit closes
+// once the success threshold is met. Default is 0 (no sampling).
+func WithHalfOpenRatio(fraction float64) Option {
+	return func(c *config) {
+		c.halfOpenRatio = fraction
+	}
+}
+
+// WithProbe runs probe, a cheap health check, before each call admitted
+// while the circuit is half-open. If probe succeeds, the call runs and its
+// outcome is recorded as usual; only calls count toward the success
+// threshold. If probe fails, as judged by the circuit's condition, the
+// failure is recorded in place of the call, which is rejected, so the
+// circuit reopens. Each admitted call uses one WithHalfOpenRequests slot
+// whether or not its probe passes. Probes are skipped in dry-run mode.
+func WithProbe(probe Func) Option {
+	return func(c *config) {
+		c.halfOpenProbe = probe
+	}
+}
+
+// WithProbeFunc runs probe as the first trial of each half-open period
+// instead of real traffic, for downstreams where a failed production call
+// is costly. The call that takes the first half-open slot runs probe in
+// its place. If probe succeeds, the circuit closes immediately, without
+// waiting for the success threshold, and the call's own fn then runs as an
+// ordinary closed-state call. If probe fails, as judged by the circuit's
+// condition, the circuit reopens and the call is rejected. Other calls
+// admitted in the same half-open period run normally. Unlike WithProbe,
+// which checks before every trial call, only one probe runs per period.
+// The probe is skipped in dry-run mode.
+func WithProbeFunc(probe Func) Option {
+	return func(c *config) {
+		c.probeFunc = probe
+	}
+}
+
+// WithProbeInterval spaces out half-open trial calls: once a trial call
+// has been admitted, further ones are rejected with ErrOpen until d has
+// passed. This slows the rate of trial calls without changing how many are
+// allowed, which WithHalfOpenRequests still controls. It is unrelated to
+// the background probe of WithActiveProbe. Default is 0 (no spacing).
+func WithProbeInterval(d time.Duration) Option {
+	return func(c *config) {
+		c.halfOpenInterval = d
+	}
+}
+
+// WithHalfOpenProbeTimeout bounds half-open trial calls with a context
+// deadline of d, so a hanging call cannot hold a half-open slot and stall
+// recovery. fn must honor its context; the resulting
+// context.DeadlineExceeded counts as a failure under the default condition
+// and reopens the circuit. Calls in other states are unaffected.
+// Default is 0 (no timeout).
+func WithHalfOpenProbeTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.halfOpenTimeout = d
+	}
+}
+
+// WithMaxHalfOpenDuration reopens the circuit if it has been half-open for
+// d without reaching the success threshold, for example because trial
+// calls hang or trickle in too slowly. The reopening counts as a failed
+// recovery for WithCooldown. Default is 0 (no limit).
+func WithMaxHalfOpenDuration(d time.Duration) Option {
+	return func(c *config) {
+		c.maxHalfOpen = d
+	}
+}
+
+// WithRecoveryRamp eases traffic back onto a recovered downstream. When
+// the circuit closes from half-open, concurrent calls are capped at the
+// WithHalfOpenRequests limit, and the cap rises linearly in ten steps to
+// ten times that limit over d, after which it is lifted. Calls over the cap
+// are rejected with ErrOpen. Default is 0 (no ramp).
+func WithRecoveryRamp(d time.Duration) Option {
+	return func(c *config) {
+		c.recoveryRamp = d
+	}
+}
+
+// WithInitialState starts the circuit in state instead of Closed, for
+// restoring a persisted circuit on startup. For Open, openedAt is when the
+// circuit originally opened, measured against the configured clock, so the
+// remaining open duration carries over; a zero openedAt means now. openedAt
+// is ignored for other states.
+func WithInitialState(state State, openedAt time.Time) Option {
+	return func(c *config) {
+		c.initialState = state
+		c.initialOpenedAt = openedAt
+	}
+}
+
+// WithWarmup sets a probation period after creation during which failures
+// are counted but cannot open the circuit. Once the period has elapsed,
+// normal tripping resumes. Default is 0 (no warm-up).
+func WithWarmup(d time.Duration) Option {
+	return func(c *config) {
+		c.warmup = d
+	}
+}
+
+// WithWarmupCalls discards the outcome of the first n calls made while
+// Closed after creation or Reset, so they neither count toward thresholds
+// nor change state. Half-open trials are always recorded, so a circuit that
+// starts Open or HalfOpen still recovers normally. Hooks still fire for
+// these calls. Default is 0 (no warm-up).
+func WithWarmupCalls(n int) Option {
+	return func(c *config) {
+		c.warmupCalls = n
+	}
+}
+
+// WithDisabled creates the circuit in passthrough mode. All calls go
+// through, no state is tracked, and no hooks fire until Enable is called.
+func WithDisabled() Option {
+	return func(c *config) {
+		c.disabled = true
+	}
+}
+
+// WithRecoverPanics recovers panics in fn and records them as failures
+// with a *PanicError, so a crashing dependency still trips the circuit.
+// Hooks and observers see the *PanicError. If repanic is true, Do then
+// panics again with the original value; otherwise it returns the
+// *PanicError. By default panics propagate without being recorded.
+func WithRecoverPanics(repanic bool) Option {
+	return func(c *config) {
+		c.recoverPanics = true
+		c.repanic = repanic
+	}
+}
+
+// WithBatchPolicy sets when a DoBatch call counts as a failure.
+// Default is BatchAnyFailure.
+func WithBatchPolicy(policy BatchPolicy) Option {
+	return func(c *config) {
+		c.batchPolicy = policy
+	}
+}
+
+// WithRWMutex lets State, Counts, and Snapshot run concurrently with each
+// other, for circuits polled far more often than they are called.
+func WithRWMutex() Option {
+	return func(c *config) {
+		c.rwMutex = true
+	}
+}
+
+// WithReentrancyCheck makes Do return ErrReentrant, without calling fn,
+// when it is called from within a function already running under the same
+// circuit. Detection relies on the context: the outer call marks the ctx
+// it passes to fn, so an inner call is caught only if it passes that ctx
+// or one derived from it. Nested calls that need their own protection
+// should go through a separate circuit. Without this option there is no
+// check and no overhead.
+func WithReentrancyCheck() Option {
+	return func(c *config) {
+		c.reentrancyCheck = true
+	}
+}
+
+// WithRichErrors makes Do wrap every error from fn in a *CircuitError that
+// records whether the call opened the circuit; see IsTrip. errors.Is and
+// errors.As see through the wrapper. Errors from a disabled circuit and
+// rejections are not wrapped. The wrapper costs an allocation per failed
+// call, so it is off by default.
+func WithRichErrors() Option {
+	return func(c *config) {
+		c.richErrors = true
+	}
+}
+
+// WithPriorityQueue lets important calls take half-open trial slots from
+// less important ones. Callers set a priority with WithCallPriority; calls
+// without one have priority 0. When the half-open quota is full, a call
+// with a higher priority than a running trial displaces the
+// lowest-priority one, preferring the most recently admitted among
+// equals: the displaced call's context is cancelled, its outcome is not
+// recorded, and it returns the open-circuit rejection. Calls of equal
+// priority never displace each other, so without priorities behavior is
+// unchanged.
+func WithPriorityQueue() Option {
+	return func(c *config) {
+		c.priorityQueue = true
+	}
+}
+
+// WithFairHalfOpen grants half-open slots in the order callers arrive
+// instead of to whichever goroutine takes the lock first, so no caller is
+// starved of trial calls and admission is deterministic in tests. While
+// the circuit is not Closed, every admission decision waits in a queue
+// behind earlier ones, which adds latency under heavy contention. Closed
+// circuits are unaffected.
+func WithFairHalfOpen() Option {
+	return func(c *config) {
+		c.fairHalfOpen = true
+	}
+}
+
+// WithDryRun puts the circuit in observe-only mode. It tracks failures,
+// transitions between states, and fires hooks as usual, but never rejects
+// calls. Use it to validate thresholds against real traffic before
+// enabling breaking.
+func WithDryRun(dryRun bool) Option {
+	return func(c *config) {
+		c.dryRun = dryRun
+	}
+}
+
+// WithAutoReset is a safety valve that resets a circuit that has gone
+// interval without closing, whether it stayed open or kept failing its
+// half-open trials. The timer starts when the circuit leaves Closed, uses
+// the configured clock, and is cancelled when the circuit closes. Use
+// OnAutoReset to detect automatic resets. Default is 0 (disabled).
+func WithAutoReset(interval time.Duration) Option {
+	return func(c *config) {
+		c.autoReset = interval
+	}
+}
+
+// WithGracefulDrain bounds how long Drain waits for in-flight calls to
+// complete. A zero timeout waits until the context passed to Drain is done.
+func WithGracefulDrain(timeout time.Duration) Option {
+	return func(c *config) {
+		c.drainTimeout = timeout
+	}
+}
+
+// WithActiveProbe calls probe in the background every interval while the
+// circuit is not closed. A successful probe moves an open circuit to
+// half-open without waiting for the open duration; a failed probe (per the
+// circuit's condition) keeps or returns it to open and restarts the open
+// duration. Probes are scheduled on the circuit's clock, so a TimerClock
+// controls them. Call Close to stop probing.
+func WithActiveProbe(interval time.Duration, probe Func) Option {
+	return func(c *config) {
+		c.probeInterval = interval
+		c.probe = probe
+	}
+}
+
+// WithBudget makes the circuit a member of a shared failure Budget.
+func WithBudget(b *Budget) Option {
+	return func(c *config) {
+		c.budget = b
+	}
+}
+
 // If sets the condition that determines whether an error counts as a failure.
-// By default, any non-nil error is a failure.
+// By default, any non-nil error is a failure except a context.Canceled
+// error caused by the caller cancelling the context passed to Do.
 func If(cond Condition) Option {
 	return func(c *config) {
 		c.condition = cond
 	}
 }
 
+// WithTripImmediatelyOn opens the circuit on any error matching cond, such
+// as revoked credentials, without waiting for the failure threshold. A
+// matching error counts as a failure even if the If condition would ignore
+// it, and it is not subject to WithFailureWeight, WithWarmup,
+// WithWarmupCalls, or WithMinClosedDuration. The circuit then recovers as
+// usual, with OnStateChange firing for each transition. Default is nil
+// (no error trips immediately).
+func WithTripImmediatelyOn(cond Condition) Option {
+	return func(c *config) {
+		c.tripOn = cond
+	}
+}
+
+// WithFailureWeight grades failures by severity: each failure adds
+// weight(err) to the failure count instead of 1, and the circuit opens when
+// the weighted count reaches the failure threshold. A weight of 0 or less
+// ignores the failure, so it neither counts nor resets the count the way a
+// success would. weight is only consulted for errors the condition counts
+// as failures. In half-open, any failure with a positive weight reopens the
+// circuit, and an ignored failure frees its trial slot for another call.
+// Default is a weight of 1 for every failure.
+func WithFailureWeight(weight func(err error) int) Option {
+	return func(c *config) {
+		c.failureWeight = weight
+	}
+}
+
+// WithCountCanceled controls whether the default condition counts
+// context.Canceled errors caused by the caller's own context as failures.
+// Default is false. It has no effect when If or WithContextCondition is set.
+func WithCountCanceled(count bool) Option {
+	return func(c *config) {
+		c.countCanceled = count
+	}
+}
+
+// WithContextCondition sets a condition that also receives the call's
+// context, for example to exclude errors caused by the caller cancelling.
+// When set, it takes precedence over If and IfNot.
+func WithContextCondition(cond ContextCondition) Option {
+	return func(c *config) {
+		c.contextCondition = cond
+	}
+}
+
 // IfNot sets a condition where matching errors are NOT counted as failures.
 // This is equivalent to If(Not(cond)).
 func IfNot(cond Condition) Option {
@@ -71,30 +631,253 @@ func Not(cond Condition) Condition {
 	}
 }
 
-// WithClock sets the clock for time operations. Useful for testing.
+// IsErr returns a condition matching errors for which errors.Is(err,
+// target) reports true, however deeply target is wrapped.
+func IsErr(target error) Condition {
+	return func(err error) bool {
+		return errors.Is(err, target)
+	}
+}
+
+// IsType returns a condition matching errors with an error of type T in
+// their chain, as found by errors.As.
+func IsType[T error]() Condition {
+	return func(err error) bool {
+		var target T
+		return errors.As(err, &target)
+	}
+}
+
+// AnyOf returns a condition matching errors that match at least one of
+// conds. conds are evaluated in order, stopping at the first match. With
+// no conds it matches nothing.
+func AnyOf(conds ...Condition) Condition {
+	return func(err error) bool {
+		for _, cond := range conds {
+			if cond(err) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AllOf returns a condition matching errors that match every one of
+// conds. conds are evaluated in order, stopping at the first mismatch.
+// With no conds it matches everything.
+func AllOf(conds ...Condition) Condition {
+	return func(err error) bool {
+		for _, cond := range conds {
+			if !cond(err) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// WithClock sets the clock for time operations. Useful for testing. If
+// clock is a TimerClock, the circuit's timers are scheduled on it too.
 func WithClock(clock Clock) Option {
 	return func(c *config) {
 		c.clock = clock
 	}
 }
 
-// OnStateChange sets a hook called when the circuit changes state.
-func OnStateChange(fn OnStateChangeFunc) Option {
+// WithClockFunc is like WithClock for a Clock whose Now is now and whose
+// AfterFunc uses real timers, for tests and time sources that only need to
+// control the current time. Timers such as WithAutoReset therefore fire in
+// real time; use WithClock with a full Clock to control them too. Like a
+// nil Clock, a nil now is invalid.
+func WithClockFunc(now func() time.Time) Option {
 	return func(c *config) {
-		c.onStateChange = fn
+		c.clock = nil
+		if now != nil {
+			c.clock = funcClock(now)
+		}
 	}
 }
 
-// OnCall sets a hook called after each call attempt.
-func OnCall(fn OnCallFunc) Option {
+// WithRand sets the source of randomness, which must return values in
+// [0, 1). Useful for deterministic tests. Default is math/rand/v2.Float64.
+func WithRand(fn func() float64) Option {
 	return func(c *config) {
-		c.onCall = fn
+		c.rand = fn
 	}
 }
 
-// OnReject sets a hook called when a call is rejected due to open circuit.
-func OnReject(fn OnRejectFunc) Option {
+// WithTags attaches metadata to the circuit, such as the owning team, for
+// use as metric labels. Tags are copied, so later changes to tags do not
+// affect the circuit. Repeated options are merged, with later values
+// winning.
+func WithTags(tags map[string]string) Option {
+	tags = maps.Clone(tags)
 	return func(c *config) {
-		c.onReject = fn
+		if c.tags == nil {
+			c.tags = make(map[string]string, len(tags))
+		}
+		maps.Copy(c.tags, tags)
 	}
 }
+
+// NameTags splits a hierarchical circuit name such as
+// "payments/stripe/charge" on "/" and labels the parts with keys in order,
+// for use with WithTags. If the name has more parts than keys, the last key
+// gets the rest of the name; keys without a part are left out.
+//
+//	breaker.NameTags("payments/stripe/charge", "service", "provider", "op")
+//	// map[op:charge provider:stripe service:payments]
+func NameTags(name string, keys ...string) map[string]string {
+	tags := make(map[string]string, len(keys))
+	parts := strings.SplitN(name, "/", max(len(keys), 1))
+	for i, part := range parts {
+		if i == len(keys) {
+			break
+		}
+		tags[keys[i]] = part
+	}
+	return tags
+}
+
+// WithNamespace qualifies the circuit's name with prefix, so circuits
+// with the same name in different parts of a binary stay distinct: hooks
+// and observers for a circuit named "database" with namespace "billing"
+// receive "billing:database". See Circuit.FullName. Default is "" (no
+// namespace).
+func WithNamespace(prefix string) Option {
+	return func(c *config) {
+		c.namespace = prefix
+	}
+}
+
+// WithCircuitID sets an identifier for this circuit instance, carried by
+// its OpenError, CircuitError, Snapshot, and events so that traces can
+// correlate a rejection with the circuit that caused it, even across
+// services that use the same circuit names. Default is a random UUID
+// generated by New.
+func WithCircuitID(id string) Option {
+	return func(c *config) {
+		c.id = id
+	}
+}
+
+// WithObserver adds an observer that receives every CircuitEvent.
+// Observers are called in the order they were added.
+func WithObserver(o Observer) Option {
+	return func(c *config) {
+		c.observers = append(c.observers, o)
+	}
+}
+
+// WithEventBuffer queues every CircuitEvent on a channel of capacity size,
+// returned by Circuit.Events, for a single consumer to drain
+// asynchronously. Publishing never blocks the circuit: when the channel is
+// full the oldest event is dropped. Events are queued after every observer
+// has seen them. Default is 0 (no queue).
+func WithEventBuffer(size int) Option {
+	return func(c *config) {
+		c.eventBuffer = size
+	}
+}
+
+// WithMaxStateChangeListeners limits the circuit to n Subscribe channels
+// at a time; further calls to Subscribe return ErrTooManyListeners until
+// a subscriber unsubscribes. Default is 0 (no limit).
+func WithMaxStateChangeListeners(n int) Option {
+	return func(c *config) {
+		c.maxListeners = n
+	}
+}
+
+// WithLatencyWindow keeps the durations of the last n executed calls for
+// Latency. Memory use is fixed at n durations. Default is 0 (latency is not
+// tracked).
+func WithLatencyWindow(n int) Option {
+	return func(c *config) {
+		c.latencyWindow = n
+	}
+}
+
+// WithSamplingRate delivers EventCall events, and so the OnCall and
+// OnCallTagged hooks, for roughly rate (between 0 and 1) of calls, chosen
+// with WithRand, to keep observers off the hot path of a very busy circuit.
+// Every other event, including state changes and rejections, is always
+// delivered, and WithLatencyWindow and WithOutcomeSink still see every
+// call. A rate of 0 delivers no EventCall events. Default is 1 (every
+// call).
+func WithSamplingRate(rate float64) Option {
+	return func(c *config) {
+		c.samplingRate = rate
+	}
+}
+
+// WithHistory keeps the last n executed calls for History, a log for debug
+// endpoints. Memory use is fixed at n entries, and errors are kept only as
+// their messages. Default is 0 (no history).
+func WithHistory(n int) Option {
+	return func(c *config) {
+		c.history = n
+	}
+}
+
+// WithOutcomeSink sets a sink that receives an Outcome for every executed
+// call, intended as an append-only audit stream. Rejected calls produce
+// no Outcome. The sink runs synchronously on the call path before Do
+// returns, so keep it fast or hand off to a buffered writer.
+func WithOutcomeSink(sink func(Outcome)) Option {
+	return func(c *config) {
+		c.outcomeSink = sink
+	}
+}
+
+// OnStateChange adds a hook called when the circuit changes state.
+func OnStateChange(fn OnStateChangeFunc) Option {
+	return WithObserver(hookObserver{onStateChange: fn})
+}
+
+// OnCall adds a hook called after each call attempt.
+func OnCall(fn OnCallFunc) Option {
+	return WithObserver(hookObserver{onCall: fn})
+}
+
+// OnCallTagged adds a hook called after each call attempt that also
+// receives the circuit's WithTags metadata.
+func OnCallTagged(fn OnCallTaggedFunc) Option {
+	return WithObserver(hookObserver{onCallTagged: fn})
+}
+
+// OnReject adds a hook called when a call is rejected due to open circuit.
+func OnReject(fn OnRejectFunc) Option {
+	return WithObserver(hookObserver{onReject: fn})
+}
+
+// OnRecover adds a hook called when successful trial calls close a
+// half-open circuit, with the total time since it left Closed. Unlike
+// OnStateChange, it does not fire when Reset or WithAutoReset closes the
+// circuit, so it pairs with an alert on opening as an all-clear.
+func OnRecover(fn OnRecoverFunc) Option {
+	return WithObserver(hookObserver{onRecover: fn})
+}
+
+// OnProbe adds a hook called with the outcome of each call evaluated while
+// the circuit is half-open, including WithProbeFunc and WithProbe
+// probes. Unlike OnCall, it ignores closed-state calls, so it measures how
+// reliably trials succeed. It runs with the circuit's lock held, so it
+// must not call back into the circuit.
+func OnProbe(fn OnProbeFunc) Option {
+	return WithObserver(hookObserver{onProbe: fn})
+}
+
+// OnRejectReason adds a hook called when a call is rejected, with the
+// reason, so metrics can tell an open circuit from an exhausted half-open
+// budget.
+func OnRejectReason(fn OnRejectReasonFunc) Option {
+	return WithObserver(hookObserver{onRejectReason: fn})
+}
+
+// OnAutoReset adds a hook called when WithAutoReset closes a circuit.
+// An automatic reset usually points to a bug in failure counting or a
+// downstream that never recovers, so it is worth alerting on.
+func OnAutoReset(fn OnAutoResetFunc) Option {
+	return WithObserver(hookObserver{onAutoReset: fn})
+}
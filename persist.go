@@ -0,0 +1,87 @@
+package breaker
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// stateVersion is the format version written by MarshalState.
+const stateVersion = 1
+
+// persistedState is the serialized form of a circuit's runtime state.
+type persistedState struct {
+	Version       int       `json:"v"`
+	State         State     `json:"state"`
+	Failures      int       `json:"failures"`
+	Successes     int       `json:"successes"`
+	OpenedAt      time.Time `json:"opened_at"`
+	LastFailureAt time.Time `json:"last_failure_at"`
+}
+
+// MarshalState serializes the circuit's runtime state: its state, counts,
+// and timestamps. In-flight calls are not included. Configuration is not included, so a circuit restored with
+// RestoreState should be created with the same options for the restored
+// counts and timestamps to make sense.
+func (c *Circuit) MarshalState() ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return json.Marshal(persistedState{
+		Version:       stateVersion,
+		State:         c.state,
+		Failures:      c.failures,
+		Successes:     c.successes,
+		OpenedAt:      c.openedAt,
+		LastFailureAt: c.lastFailureAt,
+	})
+}
+
+// RestoreState replaces the circuit's runtime state with data produced by
+// MarshalState. No hooks or observers are notified, but goroutines blocked
+// in Wait or WaitForState re-check the state. Timestamps are interpreted
+// against the circuit's clock, so an open circuit resumes its remaining
+// open duration. In-flight half-open trials are not persisted, so a
+// restored half-open circuit starts with all of its trial slots free.
+func (c *Circuit) RestoreState(data []byte) error {
+	var ps persistedState
+	if err := json.Unmarshal(data, &ps); err != nil {
+		return fmt.Errorf("breaker: restore state: %w", err)
+	}
+	if ps.Version != stateVersion {
+		return fmt.Errorf("breaker: restore state: unsupported version %d", ps.Version)
+	}
+	switch ps.State {
+	case Closed, Open, HalfOpen:
+	default:
+		return fmt.Errorf("breaker: restore state: invalid state %d", ps.State)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.state = ps.State
+	c.stateHint.Store(int32(ps.State))
+	c.failures = ps.Failures
+	c.successes = ps.Successes
+	c.halfOpenCnt = 0
+	c.halfOpenGen++
+	c.openedAt = ps.OpenedAt
+	c.downSince = ps.OpenedAt
+	c.lastFailureAt = ps.LastFailureAt
+	c.openFor = c.cfg.openDuration
+	c.publishOpenUntil()
+	c.halfOpenedAt = c.cfg.clock.Now()
+	if c.velocity != nil {
+		c.velocity.reset()
+	}
+	c.flaps = 0
+	c.closedCalls = 0
+	c.closedAt = time.Time{}
+	c.openErr = nil
+	c.lastErr = nil
+	if c.state != Closed {
+		c.openErr = c.newOpenError(nil)
+	}
+	c.armAutoReset()
+	c.notify()
+	return nil
+}
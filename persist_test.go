@@ -0,0 +1,142 @@
+package breaker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bjaus/breaker"
+	"github.com/stretchr/testify/suite"
+)
+
+type PersistSuite struct {
+	suite.Suite
+	clock *fakeClock
+}
+
+func TestPersistSuite(t *testing.T) {
+	suite.Run(t, new(PersistSuite))
+}
+
+func (s *PersistSuite) SetupTest() {
+	s.clock = newFakeClock()
+}
+
+func (s *PersistSuite) newCircuit(opts ...breaker.Option) *breaker.Circuit {
+	opts = append([]breaker.Option{
+		breaker.WithFailureThreshold(3),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.WithClock(s.clock),
+	}, opts...)
+	return breaker.New("test", opts...)
+}
+
+func (s *PersistSuite) TestRoundTrip_Closed() {
+	src := s.newCircuit()
+	for range 2 {
+		_ = src.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		})
+	}
+
+	data, err := src.MarshalState()
+	s.Require().NoError(err)
+
+	dst := s.newCircuit()
+	s.Require().NoError(dst.RestoreState(data))
+
+	s.Equal(breaker.Closed, dst.State())
+	failures := dst.Counts().Failures
+	s.Equal(2, failures)
+
+	_ = dst.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.Equal(breaker.Open, dst.State())
+}
+
+func (s *PersistSuite) TestRoundTrip_OpenKeepsRemainingDuration() {
+	src := s.newCircuit(breaker.WithFailureThreshold(1))
+	_ = src.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(40 * time.Second)
+
+	data, err := src.MarshalState()
+	s.Require().NoError(err)
+
+	dst := s.newCircuit()
+	s.Require().NoError(dst.RestoreState(data))
+
+	s.Equal(breaker.Open, dst.State())
+	s.True(src.Snapshot().OpenedAt.Equal(dst.Snapshot().OpenedAt))
+
+	s.clock.Advance(20 * time.Second)
+	s.Equal(breaker.HalfOpen, dst.State())
+}
+
+func (s *PersistSuite) TestRestoreState_FiresNoHooks() {
+	src := s.newCircuit(breaker.WithFailureThreshold(1))
+	_ = src.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	data, err := src.MarshalState()
+	s.Require().NoError(err)
+
+	stateChanges := 0
+	dst := s.newCircuit(breaker.OnStateChange(func(name string, from, to breaker.State) {
+		stateChanges++
+	}))
+	s.Require().NoError(dst.RestoreState(data))
+
+	s.Equal(breaker.Open, dst.State())
+	s.Zero(stateChanges)
+}
+
+func (s *PersistSuite) TestRestoreState_RejectsInvalidData() {
+	tests := map[string]string{
+		"malformed json":      `{`,
+		"unsupported version": `{"v":99,"state":0}`,
+		"invalid state":       `{"v":1,"state":7}`,
+	}
+
+	for name, data := range tests {
+		s.Run(name, func() {
+			c := s.newCircuit()
+
+			s.Error(c.RestoreState([]byte(data)))
+			s.Equal(breaker.Closed, c.State())
+		})
+	}
+}
+
+func (s *PersistSuite) TestRestoreState_FreesHalfOpenSlots() {
+	src := s.newCircuit(breaker.WithFailureThreshold(1), breaker.WithHalfOpenRequests(1))
+	_ = src.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	s.clock.Advance(time.Minute)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		_ = src.Do(context.Background(), func(ctx context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+	defer close(release)
+
+	data, err := src.MarshalState()
+	s.Require().NoError(err)
+
+	dst := s.newCircuit(breaker.WithHalfOpenRequests(1))
+	s.Require().NoError(dst.RestoreState(data))
+
+	s.Equal(breaker.HalfOpen, dst.State())
+	s.NoError(dst.Do(context.Background(), func(ctx context.Context) error {
+		return nil
+	}))
+}
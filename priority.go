@@ -0,0 +1,119 @@
+package breaker
+
+import (
+	"container/heap"
+	"context"
+)
+
+type priorityKey struct{}
+
+// WithCallPriority returns a copy of ctx carrying priority p for calls made
+// with it. Only circuits with WithPriorityQueue use it; higher values are
+// more important. Calls without a priority have priority 0.
+func WithCallPriority(ctx context.Context, p int) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+func callPriority(ctx context.Context) int {
+	p, _ := ctx.Value(priorityKey{}).(int)
+	return p
+}
+
+// trial is a half-open trial call running under WithPriorityQueue.
+type trial struct {
+	priority  int
+	seq       uint64
+	cancel    context.CancelFunc
+	displaced bool
+	index     int // position in the heap, or -1 once removed
+}
+
+// trialHeap orders running trials so the first to be displaced, the one
+// with the lowest priority and, among equals, the latest admitted, is at
+// the root. It backs WithPriorityQueue.
+type trialHeap []*trial
+
+func (h trialHeap) Len() int { return len(h) }
+
+func (h trialHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority < h[j].priority
+	}
+	return h[i].seq > h[j].seq
+}
+
+func (h trialHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *trialHeap) Push(x any) {
+	t := x.(*trial)
+	t.index = len(*h)
+	*h = append(*h, t)
+}
+
+func (h *trialHeap) Pop() any {
+	old := *h
+	t := old[len(old)-1]
+	old[len(old)-1] = nil
+	t.index = -1
+	*h = old[:len(old)-1]
+	return t
+}
+
+// clear forgets every trial, for when the circuit leaves HalfOpen and the
+// running trials no longer hold slots that can be displaced.
+func (h *trialHeap) clear() {
+	for _, t := range *h {
+		t.index = -1
+	}
+	clear(*h)
+	*h = (*h)[:0]
+}
+
+// startTrial registers a half-open trial with priority taken from ctx and
+// returns the context fn should run with, which displacement cancels.
+func (c *Circuit) startTrial(ctx context.Context) (context.Context, *trial) {
+	ctx, cancel := context.WithCancel(ctx)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.trialSeq++
+	t := &trial{priority: callPriority(ctx), seq: c.trialSeq, cancel: cancel}
+	heap.Push(&c.trials, t)
+	return ctx, t
+}
+
+// endTrial unregisters t and reports whether it was displaced, in which
+// case its outcome must not be recorded. It is safe to call more than
+// once and with a nil t.
+func (c *Circuit) endTrial(t *trial) (displaced bool) {
+	if t == nil {
+		return false
+	}
+	t.cancel()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t.index >= 0 {
+		heap.Remove(&c.trials, t.index)
+	}
+	return t.displaced
+}
+
+// displace hands the half-open slot of the lowest-priority running trial
+// to a call with priority p, cancelling that trial, if the half-open quota
+// is full and the trial's priority is lower than p. It reports whether the
+// call was admitted.
+func (c *Circuit) displace(p int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state != HalfOpen || c.halfOpenCnt < c.cfg.halfOpenRequests ||
+		len(c.trials) == 0 || c.trials[0].priority >= p {
+		return false
+	}
+	t := heap.Pop(&c.trials).(*trial)
+	t.displaced = true
+	t.cancel()
+	return true
+}
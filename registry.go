@@ -0,0 +1,71 @@
+package breaker
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Registry holds circuits by name so that code sharing a dependency shares
+// its circuit, and so operators can inspect every circuit in one place.
+// Safe for concurrent use.
+type Registry struct {
+	defaults []Option
+
+	mu       sync.Mutex
+	circuits map[string]*Circuit
+}
+
+// NewRegistry creates an empty Registry whose circuits are created with
+// defaults, such as a shared hook bundle or clock.
+func NewRegistry(defaults ...Option) *Registry {
+	return &Registry{
+		defaults: slices.Clone(defaults),
+		circuits: make(map[string]*Circuit),
+	}
+}
+
+// Get returns the circuit named name, creating it if it does not exist
+// with the registry's defaults followed by opts. As with any options,
+// later settings win, so opts override the defaults, while hooks and
+// observers from both are kept. Circuits are keyed by FullName, so a
+// WithNamespace option in the defaults or opts selects among circuits
+// sharing a name. opts are otherwise ignored when the circuit already
+// exists.
+func (r *Registry) Get(name string, opts ...Option) *Circuit {
+	opts = slices.Concat(r.defaults, opts)
+	key := qualifiedName(newConfig(opts).namespace, name)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if c, ok := r.circuits[key]; ok {
+		return c
+	}
+	c := New(name, opts...)
+	r.circuits[key] = c
+	return c
+}
+
+// Lookup returns the circuit whose FullName is name and whether it
+// exists, without creating it.
+func (r *Registry) Lookup(name string) (*Circuit, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.circuits[name]
+	return c, ok
+}
+
+// Circuits returns the registered circuits sorted by FullName.
+func (r *Registry) Circuits() []*Circuit {
+	r.mu.Lock()
+	circuits := make([]*Circuit, 0, len(r.circuits))
+	for _, c := range r.circuits {
+		circuits = append(circuits, c)
+	}
+	r.mu.Unlock()
+
+	slices.SortFunc(circuits, func(a, b *Circuit) int {
+		return strings.Compare(a.fullName, b.fullName)
+	})
+	return circuits
+}
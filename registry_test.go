@@ -0,0 +1,125 @@
+package breaker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bjaus/breaker"
+	"github.com/stretchr/testify/suite"
+)
+
+type RegistrySuite struct {
+	suite.Suite
+}
+
+func TestRegistrySuite(t *testing.T) {
+	suite.Run(t, new(RegistrySuite))
+}
+
+func (s *RegistrySuite) TestGet_CreatesOnceAndReuses() {
+	reg := breaker.NewRegistry()
+
+	a := reg.Get("payments", breaker.WithFailureThreshold(1))
+	b := reg.Get("payments", breaker.WithFailureThreshold(10))
+
+	s.Same(a, b)
+	s.Equal("payments", a.Name())
+	s.Equal(1, a.Snapshot().FailureThreshold, "expected options of later Gets to be ignored")
+}
+
+func (s *RegistrySuite) TestLookup_DoesNotCreate() {
+	reg := breaker.NewRegistry()
+
+	_, ok := reg.Lookup("payments")
+	s.False(ok)
+	s.Empty(reg.Circuits())
+
+	c := reg.Get("payments")
+	got, ok := reg.Lookup("payments")
+	s.True(ok)
+	s.Same(c, got)
+}
+
+func (s *RegistrySuite) TestCircuits_SortedByName() {
+	reg := breaker.NewRegistry()
+	reg.Get("search")
+	reg.Get("auth")
+	reg.Get("payments")
+
+	var names []string
+	for _, c := range reg.Circuits() {
+		names = append(names, c.Name())
+	}
+	s.Equal([]string{"auth", "payments", "search"}, names)
+}
+
+func (s *RegistrySuite) TestGet_AppliesDefaults() {
+	var changed []string
+	reg := breaker.NewRegistry(
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Minute),
+		breaker.OnStateChange(func(name string, from, to breaker.State) {
+			changed = append(changed, name)
+		}),
+	)
+
+	c := reg.Get("payments")
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+
+	s.Equal(breaker.Open, c.State())
+	s.Equal(int64(time.Minute/time.Millisecond), c.Config().OpenDurationMs)
+	s.Equal([]string{"payments"}, changed)
+}
+
+func (s *RegistrySuite) TestGet_OptionsOverrideDefaults() {
+	var hooks []string
+	reg := breaker.NewRegistry(
+		breaker.WithFailureThreshold(1),
+		breaker.OnStateChange(func(name string, from, to breaker.State) {
+			hooks = append(hooks, "default")
+		}),
+	)
+
+	c := reg.Get("search",
+		breaker.WithFailureThreshold(3),
+		breaker.OnStateChange(func(name string, from, to breaker.State) {
+			hooks = append(hooks, "extra")
+		}),
+	)
+	other := reg.Get("auth")
+
+	s.Equal(3, c.Config().FailureThreshold)
+	s.Equal(1, other.Config().FailureThreshold, "expected overrides not to leak into other circuits")
+
+	for range 3 {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		})
+	}
+	s.Equal([]string{"default", "extra"}, hooks, "expected hooks from both to fire")
+}
+
+func (s *RegistrySuite) TestGet_KeysByFullName() {
+	reg := breaker.NewRegistry()
+
+	billing := reg.Get("database", breaker.WithNamespace("billing"))
+	search := reg.Get("database", breaker.WithNamespace("search"))
+	plain := reg.Get("database")
+
+	s.NotSame(billing, search)
+	s.NotSame(billing, plain)
+	s.Same(billing, reg.Get("database", breaker.WithNamespace("billing")))
+
+	got, ok := reg.Lookup("billing:database")
+	s.True(ok)
+	s.Same(billing, got)
+
+	var names []string
+	for _, c := range reg.Circuits() {
+		names = append(names, c.FullName())
+	}
+	s.Equal([]string{"billing:database", "database", "search:database"}, names)
+}
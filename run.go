@@ -1,10 +1,22 @@
 package breaker
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// Doer is implemented by anything that runs a Func with circuit breaker
+// protection, such as *Circuit, *Chain, and *Group.
+type Doer interface {
+	Do(ctx context.Context, fn Func) error
+}
 
 // Run executes fn and returns its result with circuit breaker protection.
-// This is a convenience wrapper for functions that return a value.
-func Run[T any](ctx context.Context, c *Circuit, fn func(context.Context) (T, error)) (T, error) {
+// This is a convenience wrapper for functions that return a value. When
+// fn runs, Run returns the value fn returned alongside the error, even if
+// the error is not nil; when the circuit rejects the call, the value is
+// zero.
+func Run[T any](ctx context.Context, c Doer, fn func(context.Context) (T, error)) (T, error) {
 	var result T
 	err := c.Do(ctx, func(ctx context.Context) error {
 		var fnErr error
@@ -13,3 +25,63 @@ func Run[T any](ctx context.Context, c *Circuit, fn func(context.Context) (T, er
 	})
 	return result, err
 }
+
+// Run2 is like Run for functions that return two values. When fn runs,
+// Run2 returns the values fn returned, even with an error; when the
+// circuit rejects the call, both values are zero.
+func Run2[A, B any](ctx context.Context, c Doer, fn func(context.Context) (A, B, error)) (A, B, error) {
+	var a A
+	var b B
+	err := c.Do(ctx, func(ctx context.Context) error {
+		var fnErr error
+		a, b, fnErr = fn(ctx)
+		return fnErr
+	})
+	return a, b, err
+}
+
+// Run3 is like Run for functions that return three values. When fn runs,
+// Run3 returns the values fn returned, even with an error; when the
+// circuit rejects the call, all values are zero.
+func Run3[A, B, C any](ctx context.Context, c Doer, fn func(context.Context) (A, B, C, error)) (A, B, C, error) {
+	var a A
+	var b B
+	var cv C
+	err := c.Do(ctx, func(ctx context.Context) error {
+		var fnErr error
+		a, b, cv, fnErr = fn(ctx)
+		return fnErr
+	})
+	return a, b, cv, err
+}
+
+// RunAll runs fn for each of items in order, each as its own call through
+// c, and returns the results aligned with items. A failed item leaves a
+// zero value in its slot and does not stop the rest. Once a call is
+// rejected because the circuit is open, the remaining items are skipped:
+// the results computed so far are returned, one per item before the
+// rejected one, and the error includes the rejection, so IsOpen reports
+// true for it. An item's own error counts as a failure even if it wraps
+// ErrOpen. Otherwise the error joins the items' failures, or is nil if
+// every item succeeded.
+func RunAll[T, R any](ctx context.Context, c Doer, items []T, fn func(context.Context, T) (R, error)) ([]R, error) {
+	results := make([]R, 0, len(items))
+	var errs []error
+	for _, item := range items {
+		ran := false
+		result, err := Run(ctx, c, func(ctx context.Context) (R, error) {
+			ran = true
+			return fn(ctx, item)
+		})
+		if !ran && IsOpen(err) {
+			return results, errors.Join(append(errs, err)...)
+		}
+		if err != nil {
+			var zero R
+			result = zero
+			errs = append(errs, err)
+		}
+		results = append(results, result)
+	}
+	return results, errors.Join(errs...)
+}
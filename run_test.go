@@ -2,6 +2,8 @@ package breaker_test
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"testing"
 
 	"github.com/bjaus/breaker"
@@ -76,15 +78,15 @@ func (s *RunSuite) TestRun_WorksWithValueTypes() {
 	s.Equal(42, result)
 }
 
-func (s *RunSuite) TestRun_ReturnsZeroValueOnError() {
+func (s *RunSuite) TestRun_ReturnsFnValueOnError() {
 	c := breaker.New("test", breaker.WithClock(s.clock))
 
 	result, err := breaker.Run(ctx(), c, func(ctx context.Context) (int, error) {
-		return 0, errTest
+		return 7, errTest
 	})
 
 	s.Require().ErrorIs(err, errTest)
-	s.Zero(result)
+	s.Equal(7, result)
 }
 
 func (s *RunSuite) TestRun_WorksWithSlices() {
@@ -114,6 +116,146 @@ func (s *RunSuite) TestRun_CountsFailures() {
 	s.Equal(breaker.Open, c.State())
 }
 
+func (s *RunSuite) TestRun2_ReturnsValuesOnSuccess() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+
+	name, count, err := breaker.Run2(ctx(), c, func(ctx context.Context) (string, int, error) {
+		return "hello", 42, nil
+	})
+
+	s.Require().NoError(err)
+	s.Equal("hello", name)
+	s.Equal(42, count)
+}
+
+func (s *RunSuite) TestRun2_ReturnsFnValuesOnError() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+
+	name, count, err := breaker.Run2(ctx(), c, func(ctx context.Context) (string, int, error) {
+		return "partial", 1, errTest
+	})
+
+	s.Require().ErrorIs(err, errTest)
+	s.Equal("partial", name)
+	s.Equal(1, count)
+}
+
+func (s *RunSuite) TestRun2_ReturnsErrOpenWhenCircuitOpen() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+	_ = c.Do(ctx(), func(ctx context.Context) error { return errTest })
+
+	name, count, err := breaker.Run2(ctx(), c, func(ctx context.Context) (string, int, error) {
+		return "should not reach", 1, nil
+	})
+
+	s.True(breaker.IsOpen(err))
+	s.Zero(name)
+	s.Zero(count)
+}
+
+func (s *RunSuite) TestRun3_ReturnsValuesOnSuccess() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+
+	name, count, ok, err := breaker.Run3(ctx(), c, func(ctx context.Context) (string, int, bool, error) {
+		return "hello", 42, true, nil
+	})
+
+	s.Require().NoError(err)
+	s.Equal("hello", name)
+	s.Equal(42, count)
+	s.True(ok)
+}
+
+func (s *RunSuite) TestRun3_ReturnsFnValuesOnError() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+
+	name, count, result, err := breaker.Run3(ctx(), c, func(ctx context.Context) (string, int, *testResult, error) {
+		return "partial", 1, &testResult{value: "partial"}, errTest
+	})
+
+	s.Require().ErrorIs(err, errTest)
+	s.Equal("partial", name)
+	s.Equal(1, count)
+	s.Equal("partial", result.value)
+	s.Equal(breaker.Open, c.State())
+}
+
+func (s *RunSuite) TestRunAll_ReturnsResultsInOrder() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+
+	results, err := breaker.RunAll(ctx(), c, []int{1, 2, 3}, func(ctx context.Context, n int) (string, error) {
+		return strconv.Itoa(n * 10), nil
+	})
+
+	s.Require().NoError(err)
+	s.Equal([]string{"10", "20", "30"}, results)
+}
+
+func (s *RunSuite) TestRunAll_ContinuesPastFailures() {
+	c := breaker.New("test", breaker.WithFailureThreshold(5), breaker.WithClock(s.clock))
+
+	results, err := breaker.RunAll(ctx(), c, []int{1, 2, 3}, func(ctx context.Context, n int) (int, error) {
+		if n == 2 {
+			return -1, errTest
+		}
+		return n, nil
+	})
+
+	s.ErrorIs(err, errTest)
+	s.False(breaker.IsOpen(err))
+	s.Equal([]int{1, 0, 3}, results)
+}
+
+func (s *RunSuite) TestRunAll_ShortCircuitsOnceOpen() {
+	c := breaker.New("test", breaker.WithFailureThreshold(2), breaker.WithClock(s.clock))
+
+	var seen []int
+	results, err := breaker.RunAll(ctx(), c, []int{1, 2, 3, 4, 5}, func(ctx context.Context, n int) (int, error) {
+		seen = append(seen, n)
+		if n >= 2 {
+			return 0, errTest
+		}
+		return n, nil
+	})
+
+	s.True(breaker.IsOpen(err))
+	s.ErrorIs(err, errTest)
+	s.Equal([]int{1, 2, 3}, seen)
+	s.Equal([]int{1, 0, 0}, results, "expected results for the items before the rejection")
+}
+
+func (s *RunSuite) TestRunAll_DownstreamOpenErrorDoesNotStop() {
+	c := breaker.New("test", breaker.WithFailureThreshold(5), breaker.WithClock(s.clock))
+	downstream := fmt.Errorf("downstream: %w", breaker.ErrOpen)
+
+	results, err := breaker.RunAll(ctx(), c, []int{1, 2, 3}, func(ctx context.Context, n int) (int, error) {
+		if n == 2 {
+			return 0, downstream
+		}
+		return n, nil
+	})
+
+	s.ErrorIs(err, downstream)
+	s.Equal([]int{1, 0, 3}, results)
+}
+
+func (s *RunSuite) TestRunAll_Empty() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+
+	results, err := breaker.RunAll(ctx(), c, nil, func(ctx context.Context, n int) (int, error) {
+		return n, nil
+	})
+
+	s.NoError(err)
+	s.Empty(results)
+}
+
 func ctx() context.Context {
 	return context.Background()
 }
@@ -0,0 +1,152 @@
+package breaker
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+)
+
+// Snapshot is a point-in-time view of a circuit's runtime state.
+type Snapshot struct {
+	// Name is the circuit's FullName.
+	Name string
+
+	// ID is the circuit's ID.
+	ID string
+
+	State     State
+	Failures  int
+	Successes int
+	InFlight  int
+
+	// FailureThreshold is the number of failures that opens the circuit,
+	// as returned by WithFailureThresholdFunc if set.
+	FailureThreshold int
+
+	// SamplingRate is the fraction of calls delivered as EventCall, per
+	// WithSamplingRate.
+	SamplingRate float64
+
+	// VelocityThreshold and VelocityWindow are the WithVelocityThreshold
+	// settings, or zero if there is no velocity threshold.
+	VelocityThreshold int
+	VelocityWindow    time.Duration
+
+	// OpenedAt is when the circuit last opened. It is zero if the circuit
+	// has never opened.
+	OpenedAt time.Time
+
+	// At is when the snapshot was taken, per the circuit's clock.
+	At time.Time
+
+	// WarmupRemaining is the number of calls left in the WithWarmupCalls period.
+	WarmupRemaining int
+
+	// CallsRemaining is the number of calls left before WithCallLimit opens
+	// the circuit, or -1 if there is no call limit.
+	CallsRemaining int
+
+	// LastErr is the message of the error returned by Err, or empty if
+	// there is none.
+	LastErr string
+
+	// Tags is a copy of the circuit's WithTags metadata, or nil if it has
+	// none.
+	Tags map[string]string
+
+	// DryRun reports whether the circuit is in observe-only mode, in which
+	// case State is the state the circuit would be in without rejecting.
+	DryRun bool
+}
+
+// Snapshot returns a consistent view of the circuit's current state.
+func (c *Circuit) Snapshot() Snapshot {
+	c.rlock()
+	defer c.runlock()
+	return Snapshot{
+		Name:      c.fullName,
+		ID:        c.id,
+		State:     c.readState(),
+		Failures:  c.failures,
+		Successes: c.successes,
+		InFlight:  int(c.inFlight.Load()),
+
+		FailureThreshold:  c.failureThreshold(),
+		SamplingRate:      c.cfg.samplingRate,
+		VelocityThreshold: c.cfg.velocityN,
+		VelocityWindow:    c.cfg.velocityWindow,
+		OpenedAt:          c.openedAt,
+		At:                c.cfg.clock.Now(),
+
+		WarmupRemaining: c.warmupLeft,
+		CallsRemaining:  c.callsRemaining(),
+		LastErr:         errString(c.lastErr),
+		Tags:            maps.Clone(c.cfg.tags),
+		DryRun:          c.cfg.dryRun,
+	}
+}
+
+func (c *Circuit) callsRemaining() int {
+	switch {
+	case c.cfg.callLimit == 0:
+		return -1
+	case c.state != Closed:
+		return 0
+	default:
+		return c.cfg.callLimit - c.closedCalls
+	}
+}
+
+// String returns a one-line summary in a fixed key=value format suitable
+// for logs, such as
+//
+//	circuit(payment-service, state=open, failures=0/5, opened=12s ago)
+//
+// The opened field is present only while the circuit is open or half-open.
+func (c *Circuit) String() string {
+	return c.Snapshot().String()
+}
+
+// GoString returns a constructor-like representation for %#v.
+func (c *Circuit) GoString() string {
+	return fmt.Sprintf("breaker.New(%q, breaker.WithFailureThreshold(%d), breaker.WithSuccessThreshold(%d), breaker.WithOpenDuration(%s), breaker.WithHalfOpenRequests(%d))",
+		c.name,
+		c.cfg.failureThreshold,
+		c.cfg.successThreshold,
+		goDuration(c.cfg.openDuration),
+		c.cfg.halfOpenRequests,
+	)
+}
+
+// String returns a one-line summary of the snapshot. See Circuit.String.
+func (s Snapshot) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "circuit(%s, state=%s, failures=%d/%d", s.Name, s.State, s.Failures, s.FailureThreshold)
+	if s.State != Closed && !s.OpenedAt.IsZero() {
+		fmt.Fprintf(&b, ", opened=%s ago", s.At.Sub(s.OpenedAt).Round(time.Second))
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// goDuration formats d as a Go expression.
+func goDuration(d time.Duration) string {
+	switch {
+	case d == 0:
+		return "0"
+	case d%time.Second == 0:
+		return fmt.Sprintf("%d*time.Second", d/time.Second)
+	case d%time.Millisecond == 0:
+		return fmt.Sprintf("%d*time.Millisecond", d/time.Millisecond)
+	default:
+		return fmt.Sprintf("%d", int64(d))
+	}
+}
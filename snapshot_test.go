@@ -0,0 +1,83 @@
+package breaker_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bjaus/breaker"
+	"github.com/stretchr/testify/suite"
+)
+
+type SnapshotSuite struct {
+	suite.Suite
+	clock *fakeClock
+}
+
+func TestSnapshotSuite(t *testing.T) {
+	suite.Run(t, new(SnapshotSuite))
+}
+
+func (s *SnapshotSuite) SetupTest() {
+	s.clock = newFakeClock()
+}
+
+func (s *SnapshotSuite) TestSnapshot_ReflectsCurrentState() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(5),
+		breaker.WithClock(s.clock),
+	)
+
+	for range 2 {
+		s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		}), errTest)
+	}
+
+	snap := c.Snapshot()
+	s.Equal("test", snap.Name)
+	s.Equal(breaker.Closed, snap.State)
+	s.Equal(2, snap.Failures)
+	s.Zero(snap.Successes)
+	s.Zero(snap.InFlight)
+	s.Equal(errTest.Error(), snap.LastErr)
+}
+
+func (s *SnapshotSuite) TestString_Closed() {
+	c := breaker.New("payment-service",
+		breaker.WithFailureThreshold(5),
+		breaker.WithClock(s.clock),
+	)
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+
+	s.Equal("circuit(payment-service, state=closed, failures=1/5)", c.String())
+	s.Equal(c.String(), fmt.Sprintf("%v", c))
+}
+
+func (s *SnapshotSuite) TestString_OpenIncludesTimeSinceOpened() {
+	c := breaker.New("payment-service",
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+
+	s.ErrorIs(c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	}), errTest)
+	s.clock.Advance(12 * time.Second)
+
+	s.Equal("circuit(payment-service, state=open, failures=0/1, opened=12s ago)", fmt.Sprintf("%s", c))
+}
+
+func (s *SnapshotSuite) TestGoString() {
+	c := breaker.New("api",
+		breaker.WithFailureThreshold(3),
+		breaker.WithOpenDuration(1500*time.Millisecond),
+	)
+
+	s.Equal(`breaker.New("api", breaker.WithFailureThreshold(3), breaker.WithSuccessThreshold(2), breaker.WithOpenDuration(1500*time.Millisecond), breaker.WithHalfOpenRequests(1))`,
+		fmt.Sprintf("%#v", c))
+}
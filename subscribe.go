@@ -0,0 +1,115 @@
+package breaker
+
+import (
+	"errors"
+	"maps"
+	"sync"
+)
+
+// ErrTooManyListeners is returned by Subscribe when the circuit already has
+// as many subscribers as WithMaxStateChangeListeners allows.
+var ErrTooManyListeners = errors.New("too many state change listeners")
+
+// Buffer sizes for the broadcaster's inbound queue and each subscriber's
+// channel. Both drop their oldest event when full.
+const (
+	broadcastBuffer  = 64
+	subscriberBuffer = 16
+)
+
+// broadcaster fans state change events out to Subscribe channels on its
+// own goroutine, so a slow subscriber never holds up a transition.
+type broadcaster struct {
+	in eventQueue
+
+	mu     sync.Mutex
+	subs   map[eventQueue]struct{}
+	closed bool
+}
+
+// Subscribe returns a channel that receives an EventStateChange for every
+// state change of the circuit, and a function that unsubscribes and closes
+// the channel. Events are delivered on a separate goroutine after the
+// transition, and each channel holds a few of them: a subscriber that
+// falls behind loses the oldest events instead of slowing the circuit.
+// Close closes every subscriber's channel.
+//
+// The first Subscribe starts the delivery goroutine, which runs until
+// Close even after every subscriber has unsubscribed, so Close a circuit
+// that has been subscribed to once it is no longer needed.
+//
+// Subscribe returns ErrTooManyListeners if WithMaxStateChangeListeners
+// limits the number of subscribers and the limit is reached, and
+// ErrShutdown after Close.
+func (c *Circuit) Subscribe() (<-chan CircuitEvent, func(), error) {
+	if c.shutdown.Load() {
+		return nil, nil, ErrShutdown
+	}
+	c.mu.Lock()
+	if c.broadcast == nil {
+		c.broadcast = &broadcaster{
+			in:   make(eventQueue, broadcastBuffer),
+			subs: make(map[eventQueue]struct{}),
+		}
+		go c.broadcast.run(c.stop)
+	}
+	b := c.broadcast
+	c.mu.Unlock()
+	return b.subscribe(c.cfg.maxListeners)
+}
+
+// publish queues e for the subscribers. It never blocks, so it is safe to
+// call with the circuit's lock held.
+func (b *broadcaster) publish(e CircuitEvent) {
+	b.in.Observe(e)
+}
+
+func (b *broadcaster) run(stop <-chan struct{}) {
+	for {
+		select {
+		case e := <-b.in:
+			tags := e.Tags
+			b.mu.Lock()
+			for q := range b.subs {
+				e.Tags = maps.Clone(tags)
+				q.Observe(e)
+			}
+			b.mu.Unlock()
+		case <-stop:
+			b.mu.Lock()
+			b.closed = true
+			for q := range b.subs {
+				close(q)
+			}
+			b.subs = nil
+			b.mu.Unlock()
+			return
+		}
+	}
+}
+
+func (b *broadcaster) subscribe(limit int) (<-chan CircuitEvent, func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, nil, ErrShutdown
+	}
+	if limit > 0 && len(b.subs) >= limit {
+		return nil, nil, ErrTooManyListeners
+	}
+	q := make(eventQueue, subscriberBuffer)
+	b.subs[q] = struct{}{}
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if _, ok := b.subs[q]; ok {
+				delete(b.subs, q)
+				close(q)
+			}
+		})
+	}
+	return q, unsubscribe, nil
+}
@@ -0,0 +1,199 @@
+package breaker_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bjaus/breaker"
+	"github.com/stretchr/testify/suite"
+)
+
+type SubscribeSuite struct {
+	suite.Suite
+	clock *fakeClock
+}
+
+func TestSubscribeSuite(t *testing.T) {
+	suite.Run(t, new(SubscribeSuite))
+}
+
+func (s *SubscribeSuite) SetupTest() {
+	s.clock = newFakeClock()
+}
+
+// receive returns the next event on ch, failing the test if none arrives.
+func (s *SubscribeSuite) receive(ch <-chan breaker.CircuitEvent) breaker.CircuitEvent {
+	select {
+	case e, ok := <-ch:
+		s.Require().True(ok, "channel closed")
+		return e
+	case <-time.After(time.Second):
+		s.FailNow("timed out waiting for event")
+		return breaker.CircuitEvent{}
+	}
+}
+
+func (s *SubscribeSuite) TestSubscribe_FansOutStateChanges() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+	first, unsubFirst, err := c.Subscribe()
+	s.Require().NoError(err)
+	defer unsubFirst()
+	second, unsubSecond, err := c.Subscribe()
+	s.Require().NoError(err)
+	defer unsubSecond()
+
+	_ = c.Do(context.Background(), func(ctx context.Context) error {
+		return errTest
+	})
+	c.ResetAndReport()
+
+	for _, ch := range []<-chan breaker.CircuitEvent{first, second} {
+		e := s.receive(ch)
+		s.Equal(breaker.EventStateChange, e.Kind)
+		s.Equal(breaker.Closed, e.From)
+		s.Equal(breaker.Open, e.To)
+		e = s.receive(ch)
+		s.Equal(breaker.Open, e.From)
+		s.Equal(breaker.Closed, e.To)
+	}
+}
+
+func (s *SubscribeSuite) TestSubscribe_EachSubscriberOwnsItsTags() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithTags(map[string]string{"team": "billing"}),
+		breaker.WithClock(s.clock),
+	)
+	var received, stale atomic.Int32
+	var wg sync.WaitGroup
+	var unsubscribes []func()
+	for range 3 {
+		ch, unsubscribe, err := c.Subscribe()
+		s.Require().NoError(err)
+		unsubscribes = append(unsubscribes, unsubscribe)
+		wg.Go(func() {
+			for e := range ch {
+				received.Add(1)
+				if e.Tags["team"] != "billing" {
+					stale.Add(1)
+				}
+				e.Tags["team"] = "changed"
+			}
+		})
+	}
+
+	for range 100 {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		})
+		c.ResetAndReport()
+	}
+	s.Eventually(func() bool { return received.Load() > 0 }, time.Second, time.Millisecond)
+	for _, unsubscribe := range unsubscribes {
+		unsubscribe()
+	}
+	wg.Wait()
+
+	s.Zero(stale.Load(), "a subscriber saw another subscriber's change")
+	s.Equal(map[string]string{"team": "billing"}, c.Tags())
+}
+
+func (s *SubscribeSuite) TestSubscribe_SlowSubscriberDoesNotBlockTransitions() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+	_, unsub, err := c.Subscribe()
+	s.Require().NoError(err)
+	defer unsub()
+
+	for range 1000 {
+		_ = c.Do(context.Background(), func(ctx context.Context) error {
+			return errTest
+		})
+		c.ResetAndReport()
+	}
+
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *SubscribeSuite) TestSubscribe_EnforcesListenerLimit() {
+	c := breaker.New("test",
+		breaker.WithMaxStateChangeListeners(1),
+		breaker.WithClock(s.clock),
+	)
+
+	_, unsub, err := c.Subscribe()
+	s.Require().NoError(err)
+	_, _, err = c.Subscribe()
+	s.ErrorIs(err, breaker.ErrTooManyListeners)
+
+	unsub()
+	unsub()
+	_, unsub, err = c.Subscribe()
+	s.Require().NoError(err)
+	unsub()
+}
+
+func (s *SubscribeSuite) TestSubscribe_UnsubscribeClosesChannel() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+
+	ch, unsub, err := c.Subscribe()
+	s.Require().NoError(err)
+	unsub()
+
+	_, ok := <-ch
+	s.False(ok)
+}
+
+func (s *SubscribeSuite) TestSubscribe_CloseEndsSubscriptions() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+
+	ch, unsub, err := c.Subscribe()
+	s.Require().NoError(err)
+	s.Require().NoError(c.Close())
+
+	select {
+	case _, ok := <-ch:
+		s.False(ok)
+	case <-time.After(time.Second):
+		s.Fail("expected Close to close the channel")
+	}
+	unsub()
+
+	_, _, err = c.Subscribe()
+	s.ErrorIs(err, breaker.ErrShutdown)
+}
+
+func (s *SubscribeSuite) TestSubscribe_ConcurrentSubscribers() {
+	c := breaker.New("test",
+		breaker.WithMaxStateChangeListeners(10),
+		breaker.WithClock(s.clock),
+	)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var accepted, rejected int
+	for range 20 {
+		wg.Go(func() {
+			_, _, err := c.Subscribe()
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				rejected++
+				return
+			}
+			accepted++
+		})
+	}
+	wg.Wait()
+
+	s.Equal(10, accepted)
+	s.Equal(10, rejected)
+}
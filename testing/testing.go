@@ -0,0 +1,166 @@
+// Package testing provides helpers for tests that exercise circuit breakers.
+//
+// It imports the standard library testing package and must not be imported
+// by non-test code.
+//
+//	sim := breakertesting.NewSimulatedCircuit("api",
+//	    breaker.WithOpenDuration(30*time.Second),
+//	)
+//	breakertesting.MustTrip(t, sim.Circuit)
+//	sim.Advance(30 * time.Second)
+//	breakertesting.MustHalfOpen(t, sim.Circuit)
+package testing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	stdtesting "testing"
+	"time"
+
+	"github.com/bjaus/breaker"
+)
+
+// maxTripCalls bounds how many failing calls MustTrip makes.
+const maxTripCalls = 1000
+
+var errTrip = errors.New("breakertesting: induced failure")
+
+// Clock is a manually advanced breaker.TimerClock. Safe for concurrent use.
+// As the TimerClock contract requires, each AfterFunc callback runs in its
+// own goroutine once Advance or Set makes it due. Advance and Set return
+// only after those callbacks have returned, so a test sees their effects
+// without waiting; a callback must therefore not block on the goroutine
+// that advanced the clock.
+type Clock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*timer
+}
+
+type timer struct {
+	at      time.Time
+	f       func()
+	stopped bool
+}
+
+// NewClock creates a Clock set to the current time.
+func NewClock() *Clock {
+	return &Clock{now: time.Now()}
+}
+
+// Now returns the clock's current time.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// AfterFunc schedules f to run once the clock has advanced by d.
+func (c *Clock) AfterFunc(d time.Duration, f func()) func() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &timer{at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		t.stopped = true
+	}
+}
+
+// Advance moves the clock forward by d.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+	c.fire()
+}
+
+// Set moves the clock to t.
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	c.now = t
+	c.mu.Unlock()
+	c.fire()
+}
+
+// fire runs the callbacks of timers that are due, each in its own
+// goroutine and outside the lock so they may use the clock, and waits for
+// them to return.
+func (c *Clock) fire() {
+	c.mu.Lock()
+	var due []*timer
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		switch {
+		case t.stopped:
+		case !t.at.After(c.now):
+			t.stopped = true
+			due = append(due, t)
+		default:
+			pending = append(pending, t)
+		}
+	}
+	c.timers = pending
+	c.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, t := range due {
+		wg.Go(t.f)
+	}
+	wg.Wait()
+}
+
+// SimulatedCircuit is a circuit driven by its own Clock.
+type SimulatedCircuit struct {
+	*breaker.Circuit
+	*Clock
+}
+
+// NewSimulatedCircuit creates a circuit whose clock is controlled by the
+// returned SimulatedCircuit. Any WithClock in opts is overridden.
+func NewSimulatedCircuit(name string, opts ...breaker.Option) *SimulatedCircuit {
+	clock := NewClock()
+	opts = append(opts, breaker.WithClock(clock))
+	return &SimulatedCircuit{
+		Circuit: breaker.New(name, opts...),
+		Clock:   clock,
+	}
+}
+
+// MustTrip makes failing calls through c until it opens, and fails the
+// test if it is not open afterwards.
+func MustTrip(t stdtesting.TB, c *breaker.Circuit) {
+	t.Helper()
+	for range maxTripCalls {
+		if c.State() == breaker.Open {
+			return
+		}
+		_ = c.Do(context.Background(), func(context.Context) error {
+			return errTrip
+		})
+	}
+	if state := c.State(); state != breaker.Open {
+		t.Fatalf("circuit %q: expected open after %d failures, got %s", c.Name(), maxTripCalls, state)
+	}
+}
+
+// MustClose fails the test if c is not closed.
+func MustClose(t stdtesting.TB, c *breaker.Circuit) {
+	t.Helper()
+	mustBe(t, c, breaker.Closed)
+}
+
+// MustHalfOpen fails the test if c is not half-open.
+func MustHalfOpen(t stdtesting.TB, c *breaker.Circuit) {
+	t.Helper()
+	mustBe(t, c, breaker.HalfOpen)
+}
+
+func mustBe(t stdtesting.TB, c *breaker.Circuit, want breaker.State) {
+	t.Helper()
+	if state := c.State(); state != want {
+		t.Fatalf("circuit %q: expected %s, got %s", c.Name(), want, state)
+	}
+}
@@ -0,0 +1,165 @@
+package testing_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bjaus/breaker"
+	breakertesting "github.com/bjaus/breaker/testing"
+	"github.com/stretchr/testify/suite"
+)
+
+// recorder captures Fatalf calls instead of stopping the test.
+type recorder struct {
+	testing.TB
+	failed  bool
+	message string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.failed = true
+	r.message = fmt.Sprintf(format, args...)
+}
+
+type TestingSuite struct {
+	suite.Suite
+}
+
+func TestTestingSuite(t *testing.T) {
+	suite.Run(t, new(TestingSuite))
+}
+
+func (s *TestingSuite) TestClock_Advance() {
+	clock := breakertesting.NewClock()
+	start := clock.Now()
+
+	clock.Advance(time.Minute)
+
+	s.Equal(time.Minute, clock.Now().Sub(start))
+}
+
+func (s *TestingSuite) TestClock_Set() {
+	clock := breakertesting.NewClock()
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	clock.Set(at)
+
+	s.Equal(at, clock.Now())
+}
+
+func (s *TestingSuite) TestClock_AfterFunc() {
+	clock := breakertesting.NewClock()
+
+	fired := 0
+	clock.AfterFunc(time.Minute, func() { fired++ })
+	stop := clock.AfterFunc(time.Minute, func() { fired++ })
+	stop()
+
+	clock.Advance(59 * time.Second)
+	s.Zero(fired)
+
+	clock.Advance(time.Second)
+	s.Equal(1, fired)
+
+	clock.Advance(time.Hour)
+	s.Equal(1, fired)
+}
+
+func (s *TestingSuite) TestClock_AfterFuncRunsCallbacksConcurrently() {
+	clock := breakertesting.NewClock()
+	ready := make(chan struct{})
+
+	var order []string
+	clock.AfterFunc(time.Minute, func() {
+		<-ready
+		order = append(order, "waiter")
+	})
+	clock.AfterFunc(time.Minute, func() {
+		close(ready)
+	})
+
+	clock.Advance(time.Minute)
+
+	s.Equal([]string{"waiter"}, order)
+}
+
+func (s *TestingSuite) TestSimulatedCircuit_ControlsTime() {
+	sim := breakertesting.NewSimulatedCircuit("test",
+		breaker.WithFailureThreshold(3),
+		breaker.WithOpenDuration(10*time.Second),
+	)
+
+	breakertesting.MustTrip(s.T(), sim.Circuit)
+	s.Equal(breaker.Open, sim.State())
+
+	sim.Advance(10 * time.Second)
+
+	breakertesting.MustHalfOpen(s.T(), sim.Circuit)
+}
+
+func (s *TestingSuite) TestSimulatedCircuit_WaitForStateFollowsClock() {
+	sim := breakertesting.NewSimulatedCircuit("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithOpenDuration(time.Hour),
+	)
+	breakertesting.MustTrip(s.T(), sim.Circuit)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- sim.WaitForState(context.Background(), breaker.HalfOpen)
+	}()
+
+	select {
+	case <-done:
+		s.Fail("WaitForState returned before the clock advanced")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	sim.Advance(time.Hour)
+
+	select {
+	case err := <-done:
+		s.NoError(err)
+	case <-time.After(time.Second):
+		s.Fail("WaitForState did not wake when the clock advanced")
+	}
+}
+
+func (s *TestingSuite) TestMustClose_PassesWhenClosed() {
+	r := &recorder{TB: s.T()}
+
+	breakertesting.MustClose(r, breaker.New("test"))
+
+	s.False(r.failed)
+}
+
+func (s *TestingSuite) TestMustClose_FailsWhenOpen() {
+	sim := breakertesting.NewSimulatedCircuit("test", breaker.WithFailureThreshold(1))
+	breakertesting.MustTrip(s.T(), sim.Circuit)
+	r := &recorder{TB: s.T()}
+
+	breakertesting.MustClose(r, sim.Circuit)
+
+	s.True(r.failed)
+	s.Contains(r.message, `circuit "test": expected closed, got open`)
+}
+
+func (s *TestingSuite) TestMustHalfOpen_FailsWhenClosed() {
+	r := &recorder{TB: s.T()}
+
+	breakertesting.MustHalfOpen(r, breaker.New("test"))
+
+	s.True(r.failed)
+}
+
+func (s *TestingSuite) TestMustTrip_FailsWhenCircuitCannotOpen() {
+	r := &recorder{TB: s.T()}
+
+	breakertesting.MustTrip(r, breaker.New("test", breaker.WithDisabled()))
+
+	s.True(r.failed)
+}
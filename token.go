@@ -0,0 +1,134 @@
+package breaker
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// tokenDropped, if set, is called with the circuit's FullName when a Token
+// is collected without being reported. Tests set it to catch leaks.
+var tokenDropped atomic.Pointer[func(name string)]
+
+// Token is a call admitted by Allow whose outcome the caller reports with
+// Succeed or Fail. Only the first report counts. Safe for concurrent use.
+type Token struct {
+	c       *Circuit
+	state   State
+	gen     uint64
+	start   time.Time
+	timed   bool
+	done    atomic.Bool
+	cleanup runtime.Cleanup
+}
+
+// Allow admits a call like Do without running a function, for APIs that
+// make wrapping the call in a closure awkward. It returns the rejection Do
+// would return, or a Token on which the caller reports the outcome of its
+// operation. The report updates the circuit as if the operation had been
+// the function passed to Do.
+//
+// Allow has no context, so WithProbeFunc and WithProbe probes run
+// with context.Background, and the call does not take part in
+// WithPriorityQueue, WithReentrancyCheck, or Drain. A Token that is never
+// reported has no effect once garbage collected: a half-open trial it held
+// is given back, as if the call had never been admitted. Until then the
+// trial stays taken, so report every Token.
+func (c *Circuit) Allow() (*Token, error) {
+	if c.shutdown.Load() {
+		return nil, ErrShutdown
+	}
+	if c.draining.Load() {
+		return nil, ErrDraining
+	}
+	if c.gate != nil && !c.gate() {
+		return &Token{}, nil
+	}
+	if c.disabled.Load() {
+		c.totalCalls.Add(1)
+		return &Token{}, nil
+	}
+
+	state, probe, gen, err := c.allow()
+	if err != nil {
+		c.reject(state)
+		return nil, err
+	}
+
+	ctx := context.Background()
+	if probe {
+		if _, err := c.runProbeFunc(ctx); err != nil {
+			return nil, err
+		}
+		state = Closed
+	}
+	if state == HalfOpen && c.cfg.halfOpenProbe != nil && !c.cfg.dryRun {
+		if _, err := c.runProbe(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	c.totalCalls.Add(1)
+	t := &Token{
+		c:     c,
+		state: state,
+		gen:   gen,
+		timed: c.timed(),
+	}
+	if t.timed {
+		t.start = c.cfg.clock.Now()
+	}
+	if state == HalfOpen || tokenDropped.Load() != nil {
+		t.cleanup = runtime.AddCleanup(t, dropToken, droppedToken{
+			c:        c,
+			halfOpen: state == HalfOpen,
+			gen:      gen,
+		})
+	}
+	return t, nil
+}
+
+// Succeed reports that the operation succeeded.
+func (t *Token) Succeed() {
+	t.report(nil)
+}
+
+// Fail reports that the operation failed with err. Like an error returned
+// to Do, err goes through the circuit's condition, so a nil err or one
+// the condition ignores is not counted as a failure, and an err from
+// Unrecorded gives the admission back without recording an outcome.
+func (t *Token) Fail(err error) {
+	t.report(err)
+}
+
+func (t *Token) report(err error) {
+	if t.c == nil || t.done.Swap(true) {
+		return
+	}
+	t.cleanup.Stop()
+	if _, ok := err.(unrecordedError); ok {
+		if t.state == HalfOpen {
+			t.c.releaseTrial(t.gen)
+		}
+		return
+	}
+	t.c.report(context.Background(), t.state, t.start, t.timed, err, t.c.isFailure)
+}
+
+// droppedToken is what the cleanup for an unreported Token needs. It must
+// not refer to the Token, or the Token would never be collected.
+type droppedToken struct {
+	c        *Circuit
+	halfOpen bool
+	gen      uint64
+}
+
+func dropToken(d droppedToken) {
+	if d.halfOpen {
+		d.c.releaseTrial(d.gen)
+	}
+	if fn := tokenDropped.Load(); fn != nil {
+		(*fn)(d.c.fullName)
+	}
+}
@@ -0,0 +1,215 @@
+package breaker_test
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bjaus/breaker"
+	"github.com/stretchr/testify/suite"
+)
+
+type TokenSuite struct {
+	suite.Suite
+	clock *fakeClock
+}
+
+func TestTokenSuite(t *testing.T) {
+	suite.Run(t, new(TokenSuite))
+}
+
+func (s *TokenSuite) SetupTest() {
+	s.clock = newFakeClock()
+}
+
+func (s *TokenSuite) TestAllow_FailuresTripCircuit() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(2),
+		breaker.WithClock(s.clock),
+	)
+
+	for range 2 {
+		tok, err := c.Allow()
+		s.Require().NoError(err)
+		tok.Fail(errTest)
+	}
+
+	s.Equal(breaker.Open, c.State())
+	_, err := c.Allow()
+	s.True(breaker.IsOpen(err))
+	s.Equal(int64(2), c.Counts().TotalCalls)
+	s.Equal(int64(1), c.Counts().TotalRejections)
+}
+
+func (s *TokenSuite) TestAllow_SucceedClosesHalfOpenCircuit() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithSuccessThreshold(1),
+		breaker.WithOpenDuration(time.Second),
+		breaker.WithClock(s.clock),
+	)
+	tok, err := c.Allow()
+	s.Require().NoError(err)
+	tok.Fail(errTest)
+	s.clock.Advance(time.Second)
+
+	tok, err = c.Allow()
+	s.Require().NoError(err)
+	_, err = c.Allow()
+	s.True(breaker.IsOpen(err), "the only trial is taken")
+
+	tok.Succeed()
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *TokenSuite) TestToken_OnlyFirstReportCounts() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(2),
+		breaker.WithClock(s.clock),
+	)
+
+	tok, err := c.Allow()
+	s.Require().NoError(err)
+	tok.Fail(errTest)
+	tok.Fail(errTest)
+	tok.Succeed()
+
+	s.Equal(1, c.Counts().Failures)
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *TokenSuite) TestToken_FailWithIgnoredErrorIsSuccess() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.IfNot(func(err error) bool { return err == errTest }),
+		breaker.WithClock(s.clock),
+	)
+
+	tok, err := c.Allow()
+	s.Require().NoError(err)
+	tok.Fail(errTest)
+
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *TokenSuite) TestAllow_DisabledTokenRecordsNothing() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithClock(s.clock),
+	)
+	c.Disable()
+
+	tok, err := c.Allow()
+	s.Require().NoError(err)
+	tok.Fail(errTest)
+
+	c.Enable()
+	s.Equal(breaker.Closed, c.State())
+	s.Zero(c.Counts().Failures)
+}
+
+func (s *TokenSuite) TestAllow_ShutdownRejects() {
+	c := breaker.New("test", breaker.WithClock(s.clock))
+	s.Require().NoError(c.Close())
+
+	tok, err := c.Allow()
+	s.Nil(tok)
+	s.ErrorIs(err, breaker.ErrShutdown)
+}
+
+func (s *TokenSuite) TestToken_DroppedTokenReleasesHalfOpenSlot() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithSuccessThreshold(1),
+		breaker.WithOpenDuration(time.Second),
+		breaker.WithClock(s.clock),
+	)
+	tok, err := c.Allow()
+	s.Require().NoError(err)
+	tok.Fail(errTest)
+	s.clock.Advance(time.Second)
+
+	func() {
+		_, err := c.Allow()
+		s.Require().NoError(err)
+	}()
+
+	s.Eventually(func() bool {
+		runtime.GC()
+		tok, err := c.Allow()
+		if err != nil {
+			return false
+		}
+		tok.Succeed()
+		return true
+	}, 5*time.Second, 10*time.Millisecond, "expected the dropped token's trial to be given back")
+	s.Equal(breaker.Closed, c.State())
+}
+
+func (s *TokenSuite) TestToken_ReportsDroppedTokens() {
+	dropped := make(chan string, 1)
+	restore := breaker.SetTokenDropped(func(name string) {
+		dropped <- name
+	})
+	defer restore()
+	c := breaker.New("test", breaker.WithClock(s.clock))
+
+	func() {
+		_, err := c.Allow()
+		s.Require().NoError(err)
+	}()
+
+	s.Eventually(func() bool {
+		runtime.GC()
+		select {
+		case name := <-dropped:
+			return s.Equal("test", name)
+		default:
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func (s *TokenSuite) TestToken_ReportedTokenIsNotDropped() {
+	var dropped atomic.Int32
+	restore := breaker.SetTokenDropped(func(name string) {
+		dropped.Add(1)
+	})
+	defer restore()
+	c := breaker.New("test", breaker.WithClock(s.clock))
+
+	func() {
+		tok, err := c.Allow()
+		s.Require().NoError(err)
+		tok.Succeed()
+	}()
+	for range 3 {
+		runtime.GC()
+	}
+
+	s.Zero(dropped.Load())
+}
+
+func (s *TokenSuite) TestToken_UnrecordedReleasesTrial() {
+	c := breaker.New("test",
+		breaker.WithFailureThreshold(1),
+		breaker.WithSuccessThreshold(1),
+		breaker.WithOpenDuration(time.Second),
+		breaker.WithClock(s.clock),
+	)
+	tok, err := c.Allow()
+	s.Require().NoError(err)
+	tok.Fail(errTest)
+	s.clock.Advance(time.Second)
+
+	tok, err = c.Allow()
+	s.Require().NoError(err)
+	tok.Fail(breaker.Unrecorded(errTest))
+	s.Equal(breaker.HalfOpen, c.State())
+
+	tok, err = c.Allow()
+	s.Require().NoError(err, "the trial should be given back")
+	tok.Succeed()
+	s.Equal(breaker.Closed, c.State())
+}
@@ -0,0 +1,36 @@
+package breaker
+
+import "time"
+
+// velocityRing holds the times of the most recent failures for
+// WithVelocityThreshold. It is guarded by the circuit's lock.
+type velocityRing struct {
+	times []time.Time
+	next  int
+	full  bool
+}
+
+func newVelocityRing(n int) *velocityRing {
+	return &velocityRing{times: make([]time.Time, n)}
+}
+
+// add records a failure at t and reports whether the ring now holds n
+// failures within window.
+func (r *velocityRing) add(t time.Time, window time.Duration) bool {
+	r.times[r.next] = t
+	r.next++
+	if r.next == len(r.times) {
+		r.next = 0
+		r.full = true
+	}
+	if !r.full {
+		return false
+	}
+	// With the ring full, next is the oldest of the last n failures.
+	return t.Sub(r.times[r.next]) <= window
+}
+
+func (r *velocityRing) reset() {
+	r.next = 0
+	r.full = false
+}